@@ -0,0 +1,275 @@
+package monitor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor/errors"
+)
+
+// IncidentKind is the kind of condition an Incident tracks.
+type IncidentKind string
+
+// List of incident kinds.
+const (
+	DeviationIncident    IncidentKind = "deviation"     // Prices of two providers, or a provider and consensus, differ
+	StaleIncident        IncidentKind = "stale"         // A provider serves a stale quote
+	NoConsensusIncident  IncidentKind = "no_consensus"  // Too few providers answered to establish consensus
+	ProviderDownIncident IncidentKind = "provider_down" // A provider failed to return prices
+)
+
+// Incident is a condition tracked by the AlertManager from the cycle it is first observed until it clears.
+type Incident struct {
+	ID        string       // ID identifies this occurrence of the condition
+	Key       string       // Key identifies the condition, it is the same across occurrences
+	Kind      IncidentKind // Kind is the kind of condition
+	Pair      Pair         // Pair is the affected pair, zero for provider outages
+	Providers []string     // Providers are the affected providers
+
+	Difference  *PriceDifference // Difference is the last observed difference of a DeviationIncident
+	Stale       *StalePrice      // Stale is the last observed stale price of a StaleIncident
+	NoConsensus *NoConsensus     // NoConsensus is the last observation of a NoConsensusIncident
+	Failure     *ProviderError   // Failure is the last observed error of a ProviderDownIncident
+
+	StartedAt  time.Time // StartedAt is the time the condition was first observed
+	UpdatedAt  time.Time // UpdatedAt is the time the condition was last observed
+	ResolvedAt time.Time // ResolvedAt is the time the condition cleared, zero while open
+	NotifiedAt time.Time // NotifiedAt is the time of the last notification
+}
+
+// Summary returns a one line human readable description of the incident.
+func (i Incident) Summary() string {
+	switch {
+	case i.Difference != nil:
+		d := i.Difference
+		return fmt.Sprintf("price difference for pair %s between %s (%v) and %s (%v) is %.4f (%.2f%%), exceeding %s threshold %v",
+			d.Pair, d.ServiceA, d.PriceA, d.ServiceB, d.PriceB, d.Difference, d.Relative*100, d.Rule.Mode, d.Rule.Threshold)
+	case i.Stale != nil:
+		s := i.Stale
+		return fmt.Sprintf("price of pair %s from %s is stale: %s old, max age %s", s.Pair, s.Service, s.Age.Round(time.Second), s.Rule.MaxAge)
+	case i.NoConsensus != nil:
+		n := i.NoConsensus
+		return fmt.Sprintf("no consensus for pair %s: %d of %d required providers answered %v", n.Pair, len(n.Providers), n.Quorum, n.Providers)
+	case i.Failure != nil:
+		return fmt.Sprintf("provider %s is down: %s", i.Failure.Provider, i.Failure.Err)
+	}
+	return fmt.Sprintf("%s incident %s", i.Kind, i.Key)
+}
+
+// EventType is the type of an alert Event.
+type EventType string
+
+// List of event types.
+const (
+	Firing   EventType = "firing"   // Firing is emitted once, when an incident opens
+	Reminder EventType = "reminder" // Reminder is emitted periodically while an incident stays open
+	Resolved EventType = "resolved" // Resolved is emitted once, when an incident clears
+)
+
+// Event is a change in the lifecycle of an Incident.
+type Event struct {
+	Type     EventType
+	Incident Incident
+	Time     time.Time
+}
+
+// Notifier delivers alert events to a notification channel.
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// Refresher is implemented by notifiers which need every open incident each cycle, not only lifecycle events,
+// e.g. to keep alerts alive in systems expiring them.
+type Refresher interface {
+	Refresh(ctx context.Context, now time.Time, incidents []Incident) error
+}
+
+// Flusher is implemented by notifiers which hold back events, e.g. to send them in batches.
+// Flush delivers the held back events, it is called once the notifier is no longer used.
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// NewIncidents creates the incidents for the conditions observed in a cycle: raised differences,
+// stale prices, pairs without consensus and failed providers.
+func NewIncidents(diffs []PriceDifference, stale []StalePrice, noConsensus []NoConsensus, failures []*ProviderError) []Incident {
+	var incidents []Incident
+	for _, d := range diffs {
+		incidents = append(incidents, Incident{
+			Key:        string(DeviationIncident) + "|" + breachKey(d),
+			Kind:       DeviationIncident,
+			Pair:       d.Pair,
+			Providers:  []string{d.ServiceA, d.ServiceB},
+			Difference: &d,
+		})
+	}
+	for _, s := range stale {
+		incidents = append(incidents, Incident{
+			Key:       string(StaleIncident) + "|" + s.Pair.String() + "|" + s.Service,
+			Kind:      StaleIncident,
+			Pair:      s.Pair,
+			Providers: []string{s.Service},
+			Stale:     &s,
+		})
+	}
+	for _, n := range noConsensus {
+		incidents = append(incidents, Incident{
+			Key:         string(NoConsensusIncident) + "|" + n.Pair.String(),
+			Kind:        NoConsensusIncident,
+			Pair:        n.Pair,
+			Providers:   n.Providers,
+			NoConsensus: &n,
+		})
+	}
+	for _, f := range failures {
+		incidents = append(incidents, Incident{
+			Key:       string(ProviderDownIncident) + "|" + f.Provider,
+			Kind:      ProviderDownIncident,
+			Providers: []string{f.Provider},
+			Failure:   f,
+		})
+	}
+	return incidents
+}
+
+// AlertManager tracks open incidents and notifies about their lifecycle: firing once when an incident opens,
+// resolved once when it clears and, if a reminder interval is set, reminders while it stays open.
+// Events are delivered to every notifier. It is safe for concurrent use.
+type AlertManager struct {
+	mu               sync.Mutex
+	reminderInterval time.Duration
+	notifiers        []Notifier
+	incidents        map[string]*Incident
+}
+
+// NewAlertManager creates a new AlertManager fanning out events to the given notifiers.
+// A zero reminderInterval disables reminders.
+func NewAlertManager(reminderInterval time.Duration, notifiers ...Notifier) *AlertManager {
+	return &AlertManager{
+		reminderInterval: reminderInterval,
+		notifiers:        notifiers,
+		incidents:        make(map[string]*Incident),
+	}
+}
+
+// Configure replaces the reminder interval and notifiers, open incidents are kept.
+func (m *AlertManager) Configure(reminderInterval time.Duration, notifiers ...Notifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reminderInterval = reminderInterval
+	m.notifiers = notifiers
+}
+
+// Update reconciles open incidents with the conditions observed at now and notifies about the resulting events.
+// Conditions not open yet fire, open incidents missing from conditions resolve. Afterwards notifiers implementing
+// Refresher are refreshed with the open incidents. It returns the emitted events and the errors of notifiers
+// which failed to deliver them.
+//
+// Notifiers are called one after another once the incidents are reconciled, without holding the lock, and should
+// return promptly: ctx bounds their delivery and a Dispatcher queues it off the calling goroutine.
+func (m *AlertManager) Update(ctx context.Context, now time.Time, conditions []Incident) ([]Event, error) {
+	events, open, notifiers := m.reconcile(now, conditions)
+
+	var errs []error
+	for _, event := range events {
+		for _, n := range notifiers {
+			if err := n.Notify(ctx, event); err != nil {
+				errs = append(errs, errors.Wrapf(err, "unable to notify %s of %s", event.Type, event.Incident.Key))
+			}
+		}
+	}
+
+	for _, n := range notifiers {
+		if r, ok := n.(Refresher); ok {
+			if err := r.Refresh(ctx, now, open); err != nil {
+				errs = append(errs, errors.Wrap(err, "unable to refresh incidents"))
+			}
+		}
+	}
+
+	return events, errors.Join(errs...)
+}
+
+// reconcile reconciles open incidents with the conditions observed at now. It returns the resulting events,
+// the open incidents and the notifiers to deliver them to.
+func (m *AlertManager) reconcile(now time.Time, conditions []Incident) ([]Event, []Incident, []Notifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var events []Event
+	seen := make(map[string]bool, len(conditions))
+	for _, c := range conditions {
+		seen[c.Key] = true
+
+		incident, ok := m.incidents[c.Key]
+		if !ok {
+			c.ID = incidentID(c.Key, now)
+			c.StartedAt = now
+			c.UpdatedAt = now
+			c.NotifiedAt = now
+			m.incidents[c.Key] = &c
+			events = append(events, Event{Type: Firing, Incident: c, Time: now})
+			continue
+		}
+
+		incident.Providers = c.Providers
+		incident.Difference, incident.Stale, incident.NoConsensus, incident.Failure = c.Difference, c.Stale, c.NoConsensus, c.Failure
+		incident.UpdatedAt = now
+		if m.reminderInterval > 0 && now.Sub(incident.NotifiedAt) >= m.reminderInterval {
+			incident.NotifiedAt = now
+			events = append(events, Event{Type: Reminder, Incident: *incident, Time: now})
+		}
+	}
+
+	for _, key := range m.keys() {
+		if seen[key] {
+			continue
+		}
+		incident := m.incidents[key]
+		incident.ResolvedAt = now
+		incident.NotifiedAt = now
+		delete(m.incidents, key)
+		events = append(events, Event{Type: Resolved, Incident: *incident, Time: now})
+	}
+
+	return events, m.open(), m.notifiers
+}
+
+// Incidents returns a snapshot of the open incidents sorted by key.
+func (m *AlertManager) Incidents() []Incident {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.open()
+}
+
+// open returns the open incidents sorted by key. The caller must hold the lock.
+func (m *AlertManager) open() []Incident {
+	incidents := make([]Incident, 0, len(m.incidents))
+	for _, key := range m.keys() {
+		incidents = append(incidents, *m.incidents[key])
+	}
+	return incidents
+}
+
+// keys returns the sorted keys of the open incidents. The caller must hold the lock.
+func (m *AlertManager) keys() []string {
+	keys := make([]string, 0, len(m.incidents))
+	for key := range m.incidents {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// incidentID returns an identifier unique to the occurrence of the condition identified by key which started at t.
+func incidentID(key string, t time.Time) string {
+	sum := sha256.Sum256([]byte(key + "|" + t.UTC().Format(time.RFC3339Nano)))
+	return hex.EncodeToString(sum[:8])
+}
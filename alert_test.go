@@ -0,0 +1,147 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingNotifier records the events it is notified of and fails with err.
+type recordingNotifier struct {
+	events []Event
+	err    error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event Event) error {
+	n.events = append(n.events, event)
+	return n.err
+}
+
+func TestAlertManager_Update(t *testing.T) {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	diff := PriceDifference{Pair: Pair{Base: OSMO, Quote: USD}, ServiceA: "SQS", ServiceB: "CoinGecko", PriceA: 0.2, PriceB: 0.1}
+	failure := &ProviderError{Provider: "SQS", Err: errors.New("connection refused")}
+
+	n := &recordingNotifier{}
+	m := NewAlertManager(10*time.Minute, n)
+	cycle := func(i int, incidents ...Incident) []EventType {
+		events, err := m.Update(context.Background(), start.Add(time.Duration(i)*time.Minute), incidents)
+		assert.NoError(t, err)
+
+		var types []EventType
+		for _, e := range events {
+			types = append(types, e.Type)
+		}
+		return types
+	}
+
+	deviation := NewIncidents([]PriceDifference{diff}, nil, nil, nil)
+	both := NewIncidents([]PriceDifference{diff}, nil, nil, []*ProviderError{failure})
+
+	// Firing is emitted once, when the incident opens.
+	assert.Equal(t, []EventType{Firing}, cycle(0, deviation...))
+	assert.Empty(t, cycle(1, deviation...))
+	assert.Equal(t, []EventType{Firing}, cycle(2, both...))
+
+	incidents := m.Incidents()
+	assert.Len(t, incidents, 2)
+	assert.Equal(t, DeviationIncident, incidents[0].Kind)
+	assert.Equal(t, start, incidents[0].StartedAt)
+	assert.Equal(t, start.Add(2*time.Minute), incidents[0].UpdatedAt)
+	assert.Equal(t, ProviderDownIncident, incidents[1].Kind)
+
+	// A reminder is emitted once the reminder interval has passed since the last notification.
+	assert.Equal(t, []EventType{Reminder}, cycle(10, both...))
+	assert.Equal(t, []EventType{Reminder}, cycle(12, both...))
+	assert.Empty(t, cycle(13, both...))
+
+	// Resolved is emitted once the condition clears.
+	assert.Equal(t, []EventType{Resolved}, cycle(14, deviation...))
+	assert.Equal(t, []EventType{Resolved}, cycle(15))
+	assert.Empty(t, cycle(16))
+	assert.Empty(t, m.Incidents())
+
+	resolved := n.events[len(n.events)-1]
+	assert.Equal(t, n.events[0].Incident.ID, resolved.Incident.ID)
+	assert.Equal(t, start.Add(15*time.Minute), resolved.Incident.ResolvedAt)
+
+	// A new occurrence of the same condition fires again with a new identifier.
+	cycle(17, deviation...)
+	assert.Equal(t, Firing, n.events[len(n.events)-1].Type)
+	assert.NotEqual(t, n.events[0].Incident.ID, n.events[len(n.events)-1].Incident.ID)
+	assert.Len(t, n.events, 7)
+}
+
+func TestAlertManager_FanOut(t *testing.T) {
+	ok := &recordingNotifier{}
+	failing := &recordingNotifier{err: errors.New("unavailable")}
+	m := NewAlertManager(0, failing, ok)
+
+	incidents := NewIncidents(nil, nil, []NoConsensus{{Pair: Pair{Base: OSMO, Quote: USD}, Quorum: 3}}, nil)
+	events, err := m.Update(context.Background(), time.Now(), incidents)
+	assert.Len(t, events, 1)
+	assert.ErrorContains(t, err, "unable to notify firing of no_consensus|osmo/usd: unavailable")
+
+	// Every notifier receives the event regardless of failures of others.
+	assert.Len(t, ok.events, 1)
+	assert.Len(t, failing.events, 1)
+
+	// Reminders are disabled and failed notifications are not retried.
+	events, err = m.Update(context.Background(), time.Now().Add(time.Hour), incidents)
+	assert.Empty(t, events)
+	assert.NoError(t, err)
+}
+
+func TestNewIncidents(t *testing.T) {
+	pair := Pair{Base: OSMO, Quote: USD}
+	incidents := NewIncidents(
+		[]PriceDifference{{Pair: pair, ServiceA: "SQS", ServiceB: "CoinGecko"}},
+		[]StalePrice{{PriceData: PriceData{Pair: pair, Service: "SQS"}}},
+		[]NoConsensus{{Pair: pair, Providers: []string{"SQS"}}},
+		[]*ProviderError{{Provider: "CoinGecko", Err: errors.New("timeout")}},
+	)
+
+	var keys []string
+	for _, i := range incidents {
+		keys = append(keys, i.Key)
+	}
+	assert.Equal(t, []string{
+		"deviation|osmo/usd|SQS|CoinGecko",
+		"stale|osmo/usd|SQS",
+		"no_consensus|osmo/usd",
+		"provider_down|CoinGecko",
+	}, keys)
+	assert.Equal(t, "provider CoinGecko is down: timeout", incidents[3].Summary())
+}
+
+// refreshingNotifier records the incidents it is refreshed with.
+type refreshingNotifier struct {
+	recordingNotifier
+	refreshed [][]Incident
+}
+
+func (n *refreshingNotifier) Refresh(ctx context.Context, now time.Time, incidents []Incident) error {
+	n.refreshed = append(n.refreshed, incidents)
+	return nil
+}
+
+func TestAlertManager_Refresh(t *testing.T) {
+	n := &refreshingNotifier{}
+	m := NewAlertManager(0, n)
+
+	incidents := NewIncidents(nil, nil, nil, []*ProviderError{{Provider: "SQS", Err: errors.New("timeout")}})
+	for _, conditions := range [][]Incident{incidents, incidents, nil} {
+		_, err := m.Update(context.Background(), time.Now(), conditions)
+		assert.NoError(t, err)
+	}
+
+	// Open incidents are refreshed every cycle, events are notified only on changes.
+	assert.Len(t, n.events, 2)
+	assert.Len(t, n.refreshed, 3)
+	assert.Len(t, n.refreshed[0], 1)
+	assert.Len(t, n.refreshed[1], 1)
+	assert.Empty(t, n.refreshed[2])
+}
@@ -0,0 +1,926 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/errors"
+	"github.com/deividaspetraitis/price-monitor/notifier"
+	"github.com/deividaspetraitis/price-monitor/provider"
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix is the prefix of environment variables overriding configuration values.
+// Each flag can be set through an environment variable named after it, e.g. -sqs-base-url is MONITORD_SQS_BASE_URL.
+const envPrefix = "MONITORD_"
+
+// defaultNotifierTimeout bounds HTTP requests of notifiers without a timeout of their own.
+// Unlike providers, notifiers aren't bounded by the fetch deadline.
+const defaultNotifierTimeout = 10 * time.Second
+
+// defaultRateLimit is the minimum interval between Slack firings of the same condition unless configured otherwise.
+const defaultRateLimit = 15 * time.Minute
+
+// Config is the monitord configuration file. Both YAML and JSON documents are accepted.
+type Config struct {
+	HTTPAddress   string             `yaml:"http"`
+	AdminToken    string             `yaml:"admin_token"` // AdminToken is the bearer token of the admin endpoints, they are disabled if empty
+	Interval      time.Duration      `yaml:"interval"`
+	Timeout       time.Duration      `yaml:"timeout"`
+	Threshold     float64            `yaml:"threshold"`
+	ThresholdMode string             `yaml:"threshold_mode"` // ThresholdMode is one of absolute, percent or bps
+	MinProviders  int                `yaml:"min_providers"`  // MinProviders is the minimum number of prices needed to compare a pair
+	MaxAge        time.Duration      `yaml:"max_age"`        // MaxAge is the maximum age of an upstream quote, zero disables the check
+	Strategy      string             `yaml:"strategy"`       // Strategy is one of pairwise, median, trimmed_mean or reference
+	Quorum        int                `yaml:"quorum"`         // Quorum is the number of prices needed to establish consensus
+	Subject       string             `yaml:"subject"`        // Subject is the provider under test of the reference strategy
+	References    []string           `yaml:"references"`     // References are the providers Subject is compared with, in order of preference
+	Coins         []monitor.CoinInfo `yaml:"coins"`
+	Pairs         []PairConfig       `yaml:"pairs"`
+	Providers     []ProviderConfig   `yaml:"providers"`
+
+	RaiseAfter         int           `yaml:"raise_after"`          // RaiseAfter is the number of consecutive breaching cycles before a difference is raised
+	RaiseAfterDuration time.Duration `yaml:"raise_after_duration"` // RaiseAfterDuration is the breaching duration after which a difference is raised
+	ClearAfter         int           `yaml:"clear_after"`          // ClearAfter is the number of consecutive healthy cycles before a difference is cleared
+
+	// ConfidenceTolerance tolerates deviations within the confidence intervals reported by providers, e.g. Pyth
+	ConfidenceTolerance bool `yaml:"confidence_tolerance"`
+
+	Alerts AlertsConfig `yaml:"alerts"`
+}
+
+// AlertsConfig configures alert notifications.
+type AlertsConfig struct {
+	ReminderInterval time.Duration    `yaml:"reminder_interval"` // ReminderInterval is the interval between reminders of an open incident, zero disables reminders
+	MetricsURL       string           `yaml:"metrics_url"`       // MetricsURL is the public URL of the metrics endpoint linked from notifications
+	Notifiers        []NotifierConfig `yaml:"notifiers"`
+
+	// Templates are message templates of all notifiers keyed by event type, incident kind or kind.type,
+	// e.g. firing, stale or provider_down.resolved.
+	Templates map[string]string `yaml:"templates"`
+}
+
+// NotifierConfig configures a single alert notifier.
+type NotifierConfig struct {
+	Type       string         `yaml:"type"`        // Type is one of the supported notifier types, e.g. slack or pagerduty
+	Enabled    *bool          `yaml:"enabled"`     // Enabled defaults to true when omitted
+	URL        string         `yaml:"url"`         // URL is the endpoint notifications are sent to, e.g. the Slack webhook URL
+	Timeout    time.Duration  `yaml:"timeout"`     // Timeout is the HTTP client timeout of the notifier, 10s by default
+	RateLimit  *time.Duration `yaml:"rate_limit"`  // RateLimit is the minimum interval between firings of the same condition, 15m by default, 0 disables it
+	RoutingKey string         `yaml:"routing_key"` // RoutingKey is the PagerDuty integration key
+
+	Secret       string        `yaml:"secret"`        // Secret is the key signing webhook payloads
+	Pairs        []string      `yaml:"pairs"`         // Pairs, if set, limits webhook notifications to incidents of these pairs
+	Retries      *int          `yaml:"retries"`       // Retries is the number of retries of a failed webhook delivery
+	Backoff      time.Duration `yaml:"backoff"`       // Backoff is the delay before the first retry of a webhook delivery
+	RetryTimeout time.Duration `yaml:"retry_timeout"` // RetryTimeout caps the time of a webhook delivery including retries, 30s by default
+	DeadLetter   string        `yaml:"dead_letter"`   // DeadLetter is the path of the file undelivered webhook events are appended to
+
+	TTL time.Duration `yaml:"ttl"` // TTL is how long an Alertmanager alert stays firing unless refreshed, three intervals by default
+
+	Addr     string        `yaml:"addr"`     // Addr is the host:port of the SMTP server
+	From     string        `yaml:"from"`     // From is the sender of emails
+	To       []string      `yaml:"to"`       // To are the recipients of emails
+	Username string        `yaml:"username"` // Username, if set, authenticates with the SMTP server
+	Password string        `yaml:"password"` // Password authenticates Username
+	StartTLS bool          `yaml:"starttls"` // StartTLS upgrades the SMTP connection with STARTTLS
+	Digest   time.Duration `yaml:"digest"`   // Digest, if set, sends a digest of events once per interval instead of an email per event
+
+	Token  string `yaml:"token"`   // Token is the Telegram bot token
+	ChatID string `yaml:"chat_id"` // ChatID is the Telegram chat messages are sent to
+
+	Templates map[string]string `yaml:"templates"` // Templates override the message templates of all notifiers
+}
+
+// IsEnabled reports whether the notifier is enabled.
+func (n NotifierConfig) IsEnabled() bool {
+	return n.Enabled == nil || *n.Enabled
+}
+
+// PairConfig configures a monitored pair and its comparison rule.
+// A pair without settings of its own can be written as a plain base/quote string.
+type PairConfig struct {
+	Pair          string         `yaml:"pair"`
+	Threshold     *float64       `yaml:"threshold"`      // Threshold overrides the global threshold
+	ThresholdMode string         `yaml:"threshold_mode"` // ThresholdMode overrides the global threshold mode
+	MinProviders  *int           `yaml:"min_providers"`  // MinProviders overrides the global minimum number of prices
+	MaxAge        *time.Duration `yaml:"max_age"`        // MaxAge overrides the global maximum quote age
+	Strategy      string         `yaml:"strategy"`       // Strategy overrides the global comparison strategy
+	Quorum        *int           `yaml:"quorum"`         // Quorum overrides the global consensus quorum
+	Subject       string         `yaml:"subject"`        // Subject overrides the global subject provider
+	References    []string       `yaml:"references"`     // References overrides the global reference providers
+
+	ConfidenceTolerance *bool `yaml:"confidence_tolerance"` // ConfidenceTolerance overrides the global confidence tolerance
+
+	RaiseAfter         *int           `yaml:"raise_after"`          // RaiseAfter overrides the global number of breaching cycles
+	RaiseAfterDuration *time.Duration `yaml:"raise_after_duration"` // RaiseAfterDuration overrides the global breaching duration
+	ClearAfter         *int           `yaml:"clear_after"`          // ClearAfter overrides the global number of healthy cycles
+	Enabled            *bool          `yaml:"enabled"`              // Enabled defaults to true when omitted
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (p *PairConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&p.Pair)
+	}
+
+	type plain PairConfig
+	return value.Decode((*plain)(p))
+}
+
+// String returns a human readable representation of the pair configuration.
+func (p PairConfig) String() string {
+	s := p.Pair
+	if p.Threshold != nil {
+		s += fmt.Sprintf(" threshold=%v", *p.Threshold)
+	}
+	if p.ThresholdMode != "" {
+		s += " threshold_mode=" + p.ThresholdMode
+	}
+	if p.MinProviders != nil {
+		s += fmt.Sprintf(" min_providers=%d", *p.MinProviders)
+	}
+	if p.MaxAge != nil {
+		s += fmt.Sprintf(" max_age=%s", *p.MaxAge)
+	}
+	if p.Strategy != "" {
+		s += " strategy=" + p.Strategy
+	}
+	if p.Quorum != nil {
+		s += fmt.Sprintf(" quorum=%d", *p.Quorum)
+	}
+	if p.Subject != "" {
+		s += " subject=" + p.Subject
+	}
+	if p.References != nil {
+		s += fmt.Sprintf(" references=%v", p.References)
+	}
+	if p.ConfidenceTolerance != nil {
+		s += fmt.Sprintf(" confidence_tolerance=%t", *p.ConfidenceTolerance)
+	}
+	if p.RaiseAfter != nil {
+		s += fmt.Sprintf(" raise_after=%d", *p.RaiseAfter)
+	}
+	if p.RaiseAfterDuration != nil {
+		s += fmt.Sprintf(" raise_after_duration=%s", *p.RaiseAfterDuration)
+	}
+	if p.ClearAfter != nil {
+		s += fmt.Sprintf(" clear_after=%d", *p.ClearAfter)
+	}
+	if p.Enabled != nil {
+		s += fmt.Sprintf(" enabled=%t", *p.Enabled)
+	}
+	return s
+}
+
+// rule returns the comparison rule of the pair, falling back to def for values not set.
+func (p PairConfig) rule(def monitor.Rule) (monitor.Rule, error) {
+	rule := def
+	if p.Threshold != nil {
+		if *p.Threshold < 0 {
+			return rule, errors.New("threshold must not be negative")
+		}
+		rule.Threshold = *p.Threshold
+	}
+	if p.ThresholdMode != "" {
+		mode, err := monitor.ParseThresholdMode(p.ThresholdMode)
+		if err != nil {
+			return rule, err
+		}
+		rule.Mode = mode
+	}
+	if p.MinProviders != nil {
+		rule.MinProviders = *p.MinProviders
+	}
+	if p.MaxAge != nil {
+		rule.MaxAge = *p.MaxAge
+	}
+	if p.Strategy != "" {
+		strategy, err := monitor.ParseStrategy(p.Strategy)
+		if err != nil {
+			return rule, err
+		}
+		rule.Strategy = strategy
+	}
+	if p.Quorum != nil {
+		rule.Quorum = *p.Quorum
+	}
+	if p.Subject != "" {
+		rule.Subject = p.Subject
+	}
+	if p.References != nil {
+		rule.References = p.References
+	}
+	if p.ConfidenceTolerance != nil {
+		rule.ConfidenceTolerance = *p.ConfidenceTolerance
+	}
+	if p.RaiseAfter != nil {
+		rule.RaiseAfter = *p.RaiseAfter
+	}
+	if p.RaiseAfterDuration != nil {
+		rule.RaiseAfterDuration = *p.RaiseAfterDuration
+	}
+	if p.ClearAfter != nil {
+		rule.ClearAfter = *p.ClearAfter
+	}
+	if p.Enabled != nil {
+		rule.Enabled = *p.Enabled
+	}
+	if rule.Strategy == monitor.Reference && rule.Subject == "" {
+		return rule, errors.New("subject is required by the reference strategy")
+	}
+	return rule, nil
+}
+
+// ProviderConfig configures a single price provider.
+type ProviderConfig struct {
+	Type    string        `yaml:"type"`     // Type is one of the supported provider types, e.g. coingecko or sqs
+	Enabled *bool         `yaml:"enabled"`  // Enabled defaults to true when omitted
+	BaseURL string        `yaml:"base_url"` // BaseURL overrides the provider default API URL
+	Timeout time.Duration `yaml:"timeout"`  // Timeout is the HTTP client timeout of the provider
+
+	// IDs map pairs to provider specific identifiers, e.g. exchange symbols. Pairs without one
+	// are derived from the coin identifiers of the provider.
+	IDs map[string]string `yaml:"ids"`
+
+	// Name, URL, Path, Headers and Scale declare a generic json provider. URL and Path are templates in which
+	// {id}, {base} and {quote} are replaced with the identifiers of each pair, Path locates the price in the response.
+	Name    string            `yaml:"name"`    // Name is the provider name, reported with its prices
+	URL     string            `yaml:"url"`     // URL is the template of the request URL
+	Path    string            `yaml:"path"`    // Path is the template of the JSONPath-like expression locating the price, e.g. $.data.price
+	Headers map[string]string `yaml:"headers"` // Headers are sent with every request, e.g. Authorization
+	Scale   float64           `yaml:"scale"`   // Scale multiplies the located price, one when omitted
+}
+
+// IsEnabled reports whether the provider is enabled.
+func (p ProviderConfig) IsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
+}
+
+// defaultConfig returns the configuration used when no configuration file is given.
+func defaultConfig() *Config {
+	return &Config{
+		HTTPAddress: ":8080",
+		Interval:    60 * time.Second,
+		Timeout:     5 * time.Second,
+		Threshold:   0.02,
+		Pairs:       []PairConfig{{Pair: "osmo/usd"}},
+		Providers: []ProviderConfig{
+			{Type: "coingecko"},
+			{Type: "sqs", BaseURL: "http://localhost:9092"},
+		},
+	}
+}
+
+// loadConfig reads the configuration file at path on top of the defaults.
+// An empty path returns the defaults.
+func loadConfig(path string) (*Config, error) {
+	cfg := defaultConfig()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse %s", path)
+	}
+
+	return cfg, nil
+}
+
+// setFlagsFromEnv sets flags that were not given on the command line from their corresponding environment variables.
+func setFlagsFromEnv(fs *flag.FlagSet, getenv func(string) string) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || set[f.Name] {
+			return
+		}
+		name := envPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if v := getenv(name); v != "" {
+			if serr := fs.Set(f.Name, v); serr != nil {
+				err = errors.Wrapf(serr, "invalid value of %s", name)
+			}
+		}
+	})
+
+	return err
+}
+
+// applyFlags overrides configuration values with flags that were set, either on the command line or through environment variables.
+func (c *Config) applyFlags(fs *flag.FlagSet) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var err error
+	value := func(name string) string { return fs.Lookup(name).Value.String() }
+	for name := range set {
+		switch name {
+		case "http":
+			c.HTTPAddress = value(name)
+		case "admin-token":
+			c.AdminToken = value(name)
+		case "threshold":
+			if c.Threshold, err = strconv.ParseFloat(value(name), 64); err != nil {
+				return err
+			}
+		case "interval":
+			seconds, err := strconv.Atoi(value(name))
+			if err != nil {
+				return err
+			}
+			c.Interval = time.Duration(seconds) * time.Second
+		case "timeout":
+			if c.Timeout, err = time.ParseDuration(value(name)); err != nil {
+				return err
+			}
+		case "sqs-base-url":
+			for i := range c.Providers {
+				if c.Providers[i].Type == "sqs" {
+					c.Providers[i].BaseURL = value(name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// settings are the validated, ready to use, values derived from a Config.
+type settings struct {
+	registry  *monitor.Registry
+	pairs     monitor.Pairs
+	providers []monitor.Provider
+	rules     monitor.Rules
+	interval  time.Duration
+	timeout   time.Duration
+
+	reminderInterval time.Duration
+	templates        *notifier.Templates // templates are the message templates of all notifiers
+	notifiers        []monitor.Notifier
+
+	warnings []string                         // warnings are valid but likely unintended settings, e.g. pairs a provider can't price
+	skipped  map[string]map[monitor.Pair]bool // skipped are the pairs providers can't price, keyed by provider name
+
+	// instances are the providers and notifiers keyed by their configuration, later builds reuse unchanged
+	// ones so that their state, e.g. a rate limit back off or a pending digest, survives reloads
+	instances map[string]any
+}
+
+// unexpectedMissing returns the missing pairs of each provider except those it can't price, which are
+// warned about once when the settings are built.
+func (s *settings) unexpectedMissing(missing map[string]monitor.Pairs) map[string]monitor.Pairs {
+	unexpected := make(map[string]monitor.Pairs)
+	for provider, pairs := range missing {
+		for _, pair := range pairs {
+			if !s.skipped[provider][pair] {
+				unexpected[provider] = append(unexpected[provider], pair)
+			}
+		}
+	}
+	return unexpected
+}
+
+// retain forgets the breaches of the detector whose pairs, providers or comparisons are no longer monitored.
+func (s *settings) retain(d *monitor.Detector) {
+	providers := make([]string, len(s.providers))
+	for i, p := range s.providers {
+		providers[i] = p.Name()
+	}
+	d.Retain(s.pairs, providers, s.rules)
+}
+
+// instance returns the instance with the given key, if any. It is safe to call on nil settings.
+func (s *settings) instance(key string) (any, bool) {
+	if s == nil {
+		return nil, false
+	}
+	v, ok := s.instances[key]
+	return v, ok
+}
+
+// instanceKey returns the key of the instance built from the configuration v. Instances built from identical
+// configurations are told apart by their order.
+func instanceKey(kind string, v any, seen map[string]int) string {
+	b, _ := json.Marshal(v)
+	key := kind + string(b)
+	seen[key]++
+	return fmt.Sprintf("%s#%d", key, seen[key])
+}
+
+// build validates the configuration and constructs settings from it.
+// Coins defined in coinsFile, if any, are registered after the coins of the configuration file.
+// Providers whose configuration, and coins, are unchanged since prev, if any, are reused.
+func (c *Config) build(coinsFile string, prev *settings) (*settings, error) {
+	if c.Interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	if c.Timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+	if c.Threshold < 0 {
+		return nil, errors.New("threshold must not be negative")
+	}
+	if c.Alerts.ReminderInterval < 0 {
+		return nil, errors.New("alerts: reminder_interval must not be negative")
+	}
+
+	mode, err := monitor.ParseThresholdMode(c.ThresholdMode)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := monitor.ParseStrategy(c.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	rules := monitor.Rules{
+		Default: monitor.Rule{
+			Threshold:    c.Threshold,
+			Mode:         mode,
+			MinProviders: c.MinProviders,
+			MaxAge:       c.MaxAge,
+			Strategy:     strategy,
+			Quorum:       c.Quorum,
+			Subject:      c.Subject,
+			References:   c.References,
+			Enabled:      true,
+
+			RaiseAfter:         c.RaiseAfter,
+			RaiseAfterDuration: c.RaiseAfterDuration,
+			ClearAfter:         c.ClearAfter,
+
+			ConfidenceTolerance: c.ConfidenceTolerance,
+		},
+		Pairs: make(map[monitor.Pair]monitor.Rule, len(c.Pairs)),
+	}
+
+	registry := monitor.DefaultRegistry()
+	for i, coin := range c.Coins {
+		if err := registry.Register(coin); err != nil {
+			return nil, errors.Wrapf(err, "coins[%d]", i)
+		}
+	}
+	if coinsFile != "" {
+		f, err := os.Open(coinsFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load coins")
+		}
+		defer f.Close()
+
+		if err := registry.Load(f); err != nil {
+			return nil, errors.Wrap(err, "unable to load coins")
+		}
+	}
+
+	if len(c.Pairs) == 0 {
+		return nil, errors.New("at least one pair is required")
+	}
+
+	pairs := make(monitor.Pairs, 0, len(c.Pairs))
+	seen := make(map[monitor.Pair]bool, len(c.Pairs))
+	for i, pc := range c.Pairs {
+		pair, err := monitor.ParsePair(pc.Pair)
+		if err != nil {
+			return nil, errors.Wrapf(err, "pairs[%d]", i)
+		}
+		if seen[pair] {
+			return nil, errors.Newf("pairs[%d]: duplicate pair %s", i, pair)
+		}
+		if err := registry.Validate(monitor.Pairs{pair}); err != nil {
+			return nil, errors.Wrapf(err, "pairs[%d]", i)
+		}
+
+		if rules.Pairs[pair], err = pc.rule(rules.Default); err != nil {
+			return nil, errors.Wrapf(err, "pairs[%d]", i)
+		}
+
+		seen[pair] = true
+		pairs = append(pairs, pair)
+	}
+
+	instances := make(map[string]any)
+	keys := make(map[string]int)
+	reuse := prev != nil && reflect.DeepEqual(prev.registry.Coins(), registry.Coins())
+
+	var providers []monitor.Provider
+	names := make(map[string]bool)
+	for i, pc := range c.Providers {
+		if !pc.IsEnabled() {
+			continue
+		}
+		p, err := newProvider(pc, registry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "providers[%d] (%s)", i, pc.Type)
+		}
+		if names[strings.ToLower(p.Name())] {
+			return nil, errors.Newf("providers[%d] (%s): duplicate provider name %q", i, pc.Type, p.Name())
+		}
+		names[strings.ToLower(p.Name())] = true
+
+		key := instanceKey("provider", pc, keys)
+		if old, ok := prev.instance(key); ok && reuse {
+			p = old.(monitor.Provider)
+		}
+		instances[key] = p
+		providers = append(providers, p)
+	}
+
+	var warnings []string
+	skipped := make(map[string]map[monitor.Pair]bool)
+	for i, p := range providers {
+		r, ok := p.(monitor.PairResolver)
+		if !ok {
+			continue
+		}
+		for _, pair := range pairs {
+			if err := r.Resolve(pair); err != nil {
+				warnings = append(warnings, fmt.Sprintf("provider %s skips pair %s: %s", providers[i].Name(), pair, err))
+				if skipped[p.Name()] == nil {
+					skipped[p.Name()] = make(map[monitor.Pair]bool)
+				}
+				skipped[p.Name()][pair] = true
+			}
+		}
+	}
+	if len(providers) == 0 {
+		return nil, errors.New("at least one provider must be enabled")
+	}
+
+	templates, err := notifier.NewTemplates(c.Alerts.Templates)
+	if err != nil {
+		return nil, errors.Wrap(err, "alerts")
+	}
+
+	var notifiers []monitor.Notifier
+	for i, nc := range c.Alerts.Notifiers {
+		if !nc.IsEnabled() {
+			continue
+		}
+		n, err := newNotifier(nc, c)
+		if err != nil {
+			return nil, errors.Wrapf(err, "alerts: notifiers[%d] (%s)", i, nc.Type)
+		}
+
+		// The key includes the settings shared by all notifiers which newNotifier depends on.
+		key := instanceKey("notifier", struct {
+			Notifier   NotifierConfig
+			Templates  map[string]string
+			MetricsURL string
+			Interval   time.Duration
+		}{nc, c.Alerts.Templates, c.Alerts.MetricsURL, c.Interval}, keys)
+		if old, ok := prev.instance(key); ok {
+			n = old.(monitor.Notifier)
+		}
+		instances[key] = n
+		notifiers = append(notifiers, n)
+	}
+
+	return &settings{
+		registry:  registry,
+		pairs:     pairs,
+		providers: providers,
+		rules:     rules,
+		interval:  c.Interval,
+		timeout:   c.Timeout,
+
+		reminderInterval: c.Alerts.ReminderInterval,
+		templates:        templates,
+		notifiers:        notifiers,
+		warnings:         warnings,
+		skipped:          skipped,
+		instances:        instances,
+	}, nil
+}
+
+// newProvider constructs a monitor.Provider described by pc.
+func newProvider(pc ProviderConfig, registry *monitor.Registry) (monitor.Provider, error) {
+	httpClient := &http.Client{Timeout: pc.Timeout}
+
+	ids, err := parseIDs(pc.IDs, registry)
+	if err != nil {
+		return nil, err
+	}
+
+	switch pc.Type {
+	case "coingecko":
+		p := provider.NewCoinGeckoClient(registry)
+		if pc.BaseURL != "" {
+			p.BaseURL = pc.BaseURL
+		}
+		p.HTTPClient = httpClient
+		return p, nil
+
+	case "sqs":
+		if pc.BaseURL == "" {
+			return nil, errors.New("base_url is required")
+		}
+		p := provider.NewSQSClient(pc.BaseURL, registry)
+		p.HTTPClient = httpClient
+		return p, nil
+
+	case "binance":
+		p := provider.NewBinanceClient(registry)
+		if pc.BaseURL != "" {
+			p.BaseURL = pc.BaseURL
+		}
+		p.Symbols = ids
+		p.HTTPClient = httpClient
+		return p, nil
+
+	case "kraken":
+		p := provider.NewKrakenClient(registry)
+		if pc.BaseURL != "" {
+			p.BaseURL = pc.BaseURL
+		}
+		p.Pairs = ids
+		p.HTTPClient = httpClient
+		return p, nil
+
+	case "coinbase":
+		p := provider.NewCoinbaseClient(registry)
+		if pc.BaseURL != "" {
+			p.BaseURL = pc.BaseURL
+		}
+		p.Products = ids
+		p.HTTPClient = httpClient
+		return p, nil
+
+	case "osmosis":
+		if pc.BaseURL == "" {
+			return nil, errors.New("base_url is required")
+		}
+		if len(ids) == 0 {
+			return nil, errors.New("ids are required, pool ids keyed by pair")
+		}
+		for pair, pool := range ids {
+			if _, err := strconv.ParseUint(pool, 10, 64); err != nil {
+				return nil, errors.Newf("ids: %s: invalid pool id %q", pair, pool)
+			}
+		}
+		p := provider.NewOsmosisClient(pc.BaseURL, ids, registry)
+		p.HTTPClient = httpClient
+		return p, nil
+
+	case "pyth":
+		if len(ids) == 0 {
+			return nil, errors.New("ids are required, price feed ids keyed by pair")
+		}
+		for pair, feed := range ids {
+			if id, err := hex.DecodeString(provider.PythFeedID(feed)); err != nil || len(id) != 32 {
+				return nil, errors.Newf("ids: %s: invalid price feed id %q", pair, feed)
+			}
+		}
+		p := provider.NewPythClient(ids)
+		if pc.BaseURL != "" {
+			p.BaseURL = pc.BaseURL
+		}
+		p.HTTPClient = httpClient
+		return p, nil
+
+	case "json":
+		switch {
+		case pc.Name == "":
+			return nil, errors.New("name is required")
+		case pc.URL == "":
+			return nil, errors.New("url is required")
+		case pc.Path == "":
+			return nil, errors.New("path is required")
+		case pc.Scale < 0:
+			return nil, errors.New("scale must not be negative")
+		}
+		p, err := provider.NewJSONClient(pc.Name, pc.URL, pc.Path, registry)
+		if err != nil {
+			return nil, err
+		}
+		if pc.Scale != 0 {
+			p.Scale = pc.Scale
+		}
+		p.IDs = ids
+		p.Headers = pc.Headers
+		p.HTTPClient = httpClient
+		return p, nil
+
+	case "":
+		return nil, errors.New("type is required")
+	}
+
+	return nil, errors.Newf("unknown provider type %q", pc.Type)
+}
+
+// parseIDs parses the pairs of provider identifiers keyed by base/quote, the coins of the pairs must be registered.
+func parseIDs(ids map[string]string, registry *monitor.Registry) (map[monitor.Pair]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	parsed := make(map[monitor.Pair]string, len(ids))
+	for p, id := range ids {
+		pair, err := monitor.ParsePair(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "ids")
+		}
+		if id == "" {
+			return nil, errors.Newf("ids: empty identifier of pair %s", pair)
+		}
+		if err := registry.Validate(monitor.Pairs{pair}); err != nil {
+			return nil, errors.Wrap(err, "ids")
+		}
+		parsed[pair] = id
+	}
+	return parsed, nil
+}
+
+// newNotifier constructs a monitor.Notifier described by nc.
+func newNotifier(nc NotifierConfig, c *Config) (monitor.Notifier, error) {
+	rateLimit := defaultRateLimit
+	if nc.RateLimit != nil {
+		if *nc.RateLimit < 0 {
+			return nil, errors.New("rate_limit must not be negative")
+		}
+		rateLimit = *nc.RateLimit
+	}
+	if nc.Timeout < 0 {
+		return nil, errors.New("timeout must not be negative")
+	}
+	timeout := nc.Timeout
+	if timeout == 0 {
+		timeout = defaultNotifierTimeout
+	}
+	httpClient := &http.Client{Timeout: timeout}
+
+	// Templates of the notifier take precedence over the templates of all notifiers.
+	sources := maps.Clone(c.Alerts.Templates)
+	if sources == nil {
+		sources = make(map[string]string)
+	}
+	maps.Copy(sources, nc.Templates)
+	templates, err := notifier.NewTemplates(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	switch nc.Type {
+	case "slack":
+		if nc.URL == "" {
+			return nil, errors.New("url is required")
+		}
+		n := notifier.NewSlack(nc.URL, c.Alerts.MetricsURL, rateLimit)
+		n.Templates = templates
+		n.HTTPClient = httpClient
+		return n, nil
+
+	case "pagerduty":
+		if nc.RoutingKey == "" {
+			return nil, errors.New("routing_key is required")
+		}
+		n := notifier.NewPagerDuty(nc.RoutingKey)
+		if nc.URL != "" {
+			n.URL = nc.URL
+		}
+		n.Templates = templates
+		n.HTTPClient = httpClient
+		return n, nil
+
+	case "webhook":
+		if nc.URL == "" {
+			return nil, errors.New("url is required")
+		}
+		if len(nc.Templates) > 0 {
+			return nil, errors.New("templates are not supported, payloads are structured")
+		}
+		if nc.Secret == "" {
+			return nil, errors.New("secret is required")
+		}
+		n := notifier.NewWebhook(nc.URL, nc.Secret)
+		for i, p := range nc.Pairs {
+			pair, err := monitor.ParsePair(p)
+			if err != nil {
+				return nil, errors.Wrapf(err, "pairs[%d]", i)
+			}
+			n.Pairs = append(n.Pairs, pair)
+		}
+		if nc.Retries != nil {
+			if *nc.Retries < 0 {
+				return nil, errors.New("retries must not be negative")
+			}
+			n.Retries = *nc.Retries
+		}
+		if nc.Backoff > 0 {
+			n.Backoff = nc.Backoff
+		}
+		if nc.RetryTimeout < 0 {
+			return nil, errors.New("retry_timeout must not be negative")
+		}
+		if nc.RetryTimeout > 0 {
+			n.RetryTimeout = nc.RetryTimeout
+		}
+		if nc.DeadLetter != "" {
+			n.DeadLetter = appendFile(nc.DeadLetter)
+		}
+		n.HTTPClient = httpClient
+		return n, nil
+
+	case "alertmanager":
+		if nc.URL == "" {
+			return nil, errors.New("url is required")
+		}
+		ttl := nc.TTL
+		if ttl == 0 {
+			ttl = 3 * c.Interval
+		}
+		if ttl <= c.Interval {
+			return nil, errors.New("ttl must exceed the interval")
+		}
+		n := notifier.NewAlertmanager(nc.URL, ttl)
+		n.GeneratorURL = c.Alerts.MetricsURL
+		n.Templates = templates
+		n.HTTPClient = httpClient
+		return n, nil
+
+	case "email":
+		if nc.Addr == "" {
+			return nil, errors.New("addr is required")
+		}
+		if nc.From == "" {
+			return nil, errors.New("from is required")
+		}
+		if len(nc.To) == 0 {
+			return nil, errors.New("to is required")
+		}
+		if nc.Digest < 0 {
+			return nil, errors.New("digest must not be negative")
+		}
+		n := notifier.NewEmail(nc.Addr, nc.From, nc.To)
+		n.Username, n.Password = nc.Username, nc.Password
+		n.StartTLS = nc.StartTLS
+		n.Digest = nc.Digest
+		if nc.Timeout > 0 {
+			n.Timeout = nc.Timeout
+		}
+		n.Templates = templates
+		return n, nil
+
+	case "telegram":
+		if nc.Token == "" {
+			return nil, errors.New("token is required")
+		}
+		if nc.ChatID == "" {
+			return nil, errors.New("chat_id is required")
+		}
+		n := notifier.NewTelegram(nc.Token, nc.ChatID)
+		if nc.URL != "" {
+			n.BaseURL = nc.URL
+		}
+		n.Templates = templates
+		n.HTTPClient = httpClient
+		return n, nil
+
+	case "discord":
+		if nc.URL == "" {
+			return nil, errors.New("url is required")
+		}
+		n := notifier.NewDiscord(nc.URL)
+		n.Templates = templates
+		n.HTTPClient = httpClient
+		return n, nil
+
+	case "":
+		return nil, errors.New("type is required")
+	}
+
+	return nil, errors.Newf("unknown notifier type %q", nc.Type)
+}
+
+// appendFile is an io.Writer appending to the file at its path, created if it does not exist.
+// The file is opened for each write so that it can be rotated.
+type appendFile string
+
+// Write implements io.Writer.
+func (f appendFile) Write(p []byte) (int, error) {
+	file, err := os.OpenFile(string(f), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return file.Write(p)
+}
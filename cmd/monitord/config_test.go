@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/notifier"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "monitord.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfig_Build(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        string
+		expectedPairs monitor.Pairs
+		expectedError string
+	}{
+		{
+			name: "valid yaml",
+			config: `
+interval: 30s
+coins:
+  - symbol: atom
+    ids: {coingecko: cosmos, sqs: uatom}
+pairs: [osmo/usd, atom/usd]
+providers:
+  - type: coingecko
+  - type: sqs
+    base_url: http://sqs
+  - type: binance
+    ids: {atom/usd: ATOMUSDC}
+  - type: kraken
+    ids: {atom/usd: ATOMUSD}
+  - type: coinbase
+    base_url: http://coinbase
+  - type: osmosis
+    base_url: http://lcd
+    ids: {osmo/usd: "1464"}
+  - type: pyth
+    ids: {osmo/usd: "0x5867f5683c757393a0670ef0f701490950fe93fdb006d181c8265a831ac0c5c6"}
+  - type: json
+    name: Internal
+    url: http://pricing/v1/prices/{base}?quote={quote}
+    path: $.data.price
+    headers: {Authorization: Bearer token}
+    scale: 0.000001
+`,
+			expectedPairs: monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}, {Base: "atom", Quote: monitor.USD}},
+		},
+		{
+			name:          "invalid provider ids",
+			config:        `providers: [{type: binance, ids: {osmousdt: OSMOUSDT}}]`,
+			expectedError: `providers[0] (binance): ids: `,
+		},
+		{
+			name:          "valid json",
+			config:        `{"pairs": ["osmo/usd"], "providers": [{"type": "coingecko"}]}`,
+			expectedPairs: monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+		},
+		{
+			name:          "unknown coin",
+			config:        `pairs: [osmo/usd, tia/usd]`,
+			expectedError: `pairs[1]: pair tia/usd: "tia": unknown coin`,
+		},
+		{
+			name:          "duplicate pair",
+			config:        `pairs: [osmo/usd, OSMO/USD]`,
+			expectedError: "pairs[1]: duplicate pair osmo/usd",
+		},
+		{
+			name: "missing provider url",
+			config: `
+providers:
+  - type: coingecko
+  - type: sqs
+`,
+			expectedError: "providers[1] (sqs): base_url is required",
+		},
+		{
+			name:          "provider ids of unregistered coin",
+			config:        `providers: [{type: kraken, ids: {btc/usd: XBTUSD}}]`,
+			expectedError: `providers[0] (kraken): ids: `,
+		},
+		{
+			name:          "invalid osmosis pool",
+			config:        `providers: [{type: osmosis, base_url: http://lcd, ids: {osmo/usd: pool-1}}]`,
+			expectedError: `providers[0] (osmosis): ids: osmo/usd: invalid pool id "pool-1"`,
+		},
+		{
+			name:          "invalid pyth feed",
+			config:        `providers: [{type: pyth, ids: {osmo/usd: "0x5867f568"}}]`,
+			expectedError: `providers[0] (pyth): ids: osmo/usd: invalid price feed id "0x5867f568"`,
+		},
+		{
+			name:          "missing json path",
+			config:        `providers: [{type: json, name: Internal, url: http://pricing}]`,
+			expectedError: "providers[0] (json): path is required",
+		},
+		{
+			name:          "invalid json path",
+			config:        `providers: [{type: json, name: Internal, url: http://pricing, path: "data[x]"}]`,
+			expectedError: `providers[0] (json): path "data[x]": invalid index "x"`,
+		},
+		{
+			name:          "duplicate provider name",
+			config:        `providers: [{type: coingecko}, {type: json, name: coingecko, url: http://pricing, path: price}]`,
+			expectedError: `providers[1] (json): duplicate provider name "coingecko"`,
+		},
+		{
+			name:          "unknown provider",
+			config:        `providers: [{type: bitstamp}]`,
+			expectedError: `providers[0] (bitstamp): unknown provider type "bitstamp"`,
+		},
+		{
+			name: "missing notifier url",
+			config: `
+alerts:
+  notifiers:
+    - type: slack
+`,
+			expectedError: "alerts: notifiers[0] (slack): url is required",
+		},
+		{
+			name:          "missing routing key",
+			config:        `alerts: {notifiers: [{type: pagerduty}]}`,
+			expectedError: "alerts: notifiers[0] (pagerduty): routing_key is required",
+		},
+		{
+			name:          "missing webhook secret",
+			config:        `alerts: {notifiers: [{type: webhook, url: http://hook}]}`,
+			expectedError: "alerts: notifiers[0] (webhook): secret is required",
+		},
+		{
+			name:          "invalid webhook pair",
+			config:        `alerts: {notifiers: [{type: webhook, url: http://hook, secret: s, pairs: [osmo]}]}`,
+			expectedError: "alerts: notifiers[0] (webhook): pairs[0]",
+		},
+		{
+			name:          "alertmanager ttl shorter than interval",
+			config:        `{interval: 1m, alerts: {notifiers: [{type: alertmanager, url: http://am, ttl: 30s}]}}`,
+			expectedError: "alerts: notifiers[0] (alertmanager): ttl must exceed the interval",
+		},
+		{
+			name:          "missing email recipients",
+			config:        `alerts: {notifiers: [{type: email, addr: "smtp:587", from: monitor@example.com}]}`,
+			expectedError: "alerts: notifiers[0] (email): to is required",
+		},
+		{
+			name:          "missing telegram chat",
+			config:        `alerts: {notifiers: [{type: telegram, token: "123:abc"}]}`,
+			expectedError: "alerts: notifiers[0] (telegram): chat_id is required",
+		},
+		{
+			name:          "broken template",
+			config:        `alerts: {templates: {firing: "{{.Pair}}"}}`,
+			expectedError: `alerts: template "firing": template: firing:1:2: executing "firing" at <.Pair>: can't evaluate field Pair in type monitor.Event`,
+		},
+		{
+			name:          "broken notifier template",
+			config:        `alerts: {notifiers: [{type: discord, url: http://discord, templates: {stale.fired: "{{.Type}}"}}]}`,
+			expectedError: `alerts: notifiers[0] (discord): template "stale.fired": unknown event type or incident kind`,
+		},
+		{
+			name:          "unknown notifier",
+			config:        `alerts: {notifiers: [{type: carrier-pigeon}]}`,
+			expectedError: `alerts: notifiers[0] (carrier-pigeon): unknown notifier type "carrier-pigeon"`,
+		},
+		{
+			name:          "unknown field",
+			config:        `treshold: 0.1`,
+			expectedError: "field treshold not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadConfig(writeConfig(t, tt.config))
+			if err == nil {
+				var s *settings
+				if s, err = cfg.build("", nil); err == nil {
+					assert.Equal(t, tt.expectedPairs, s.pairs)
+				}
+			}
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_Overrides(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, `
+threshold: 0.5
+interval: 10s
+providers:
+  - type: sqs
+    base_url: http://from-file
+`))
+	assert.NoError(t, err)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Float64("threshold", 0.02, "")
+	fs.Int("interval", 60, "")
+	fs.String("sqs-base-url", "", "")
+	fs.String("admin-token", "", "")
+	assert.NoError(t, fs.Parse([]string{"-threshold", "0.1"}))
+
+	env := map[string]string{
+		"MONITORD_THRESHOLD":    "0.3", // ignored, flag was given on the command line
+		"MONITORD_SQS_BASE_URL": "http://from-env",
+		"MONITORD_ADMIN_TOKEN":  "secret",
+	}
+	assert.NoError(t, setFlagsFromEnv(fs, func(k string) string { return env[k] }))
+	assert.NoError(t, cfg.applyFlags(fs))
+
+	assert.Equal(t, 0.1, cfg.Threshold)
+	assert.Equal(t, 10*time.Second, cfg.Interval)
+	assert.Equal(t, "http://from-env", cfg.Providers[0].BaseURL)
+	assert.Equal(t, "secret", cfg.AdminToken)
+}
+
+func TestConfig_BuildRules(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, `
+threshold: 0.02
+min_providers: 2
+alerts:
+  reminder_interval: 30m
+  templates:
+    firing: "{{upper .Type}}: {{.Incident.Summary}}"
+  notifiers:
+    - type: slack
+      url: http://slack/hook
+      rate_limit: 10m
+    - type: slack
+      url: http://slack/disabled
+      enabled: false
+    - type: pagerduty
+      routing_key: key
+    - type: webhook
+      url: http://hook
+      secret: s
+      pairs: [osmo/usd]
+      retries: 5
+      backoff: 2s
+      retry_timeout: 10s
+    - type: alertmanager
+      url: http://alertmanager:9093
+    - type: email
+      addr: smtp:587
+      from: monitor@example.com
+      to: [ops@example.com]
+      starttls: true
+      digest: 1h
+    - type: telegram
+      token: "123:abc"
+      chat_id: "-100"
+    - type: discord
+      url: http://discord/api/webhooks/1/token
+      templates:
+        provider_down.resolved: "{{index .Incident.Providers 0}} is back"
+pairs:
+  - osmo/usd
+  - pair: atom/usd
+    threshold: 50
+    threshold_mode: bps
+    min_providers: 3
+    max_age: 5m
+    strategy: reference
+    subject: SQS
+    references: [CoinGecko]
+    raise_after: 3
+    raise_after_duration: 5m
+    clear_after: 2
+    confidence_tolerance: true
+  - pair: tia/usd
+    enabled: false
+    strategy: median
+    quorum: 3
+coins:
+  - {symbol: atom, ids: {coingecko: cosmos, sqs: uatom}}
+  - {symbol: tia, ids: {coingecko: celestia}}
+`))
+	assert.NoError(t, err)
+
+	s, err := cfg.build("", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, s.reminderInterval)
+	assert.Len(t, s.notifiers, 7)
+	if slack, ok := s.notifiers[0].(*notifier.Slack); assert.True(t, ok) {
+		assert.Equal(t, defaultNotifierTimeout, slack.HTTPClient.Timeout)
+	}
+	if webhook, ok := s.notifiers[2].(*notifier.Webhook); assert.True(t, ok) {
+		assert.Equal(t, 10*time.Second, webhook.RetryTimeout)
+	}
+	msg, ok, err := s.templates.Render(monitor.Event{Type: monitor.Firing, Incident: monitor.Incident{Kind: monitor.StaleIncident}})
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, "FIRING: stale incident ", msg)
+	assert.Equal(t, monitor.Rule{Threshold: 0.02, Mode: monitor.Absolute, MinProviders: 2, Strategy: monitor.Pairwise, Enabled: true}, s.rules.For(monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}))
+	assert.Equal(t, monitor.Rule{Threshold: 50, Mode: monitor.BasisPoints, MinProviders: 3, MaxAge: 5 * time.Minute, Strategy: monitor.Reference, Subject: "SQS", References: []string{"CoinGecko"}, Enabled: true, RaiseAfter: 3, RaiseAfterDuration: 5 * time.Minute, ClearAfter: 2, ConfidenceTolerance: true}, s.rules.For(monitor.Pair{Base: "atom", Quote: monitor.USD}))
+	assert.Equal(t, monitor.Rule{Threshold: 0.02, Mode: monitor.Absolute, MinProviders: 2, Strategy: monitor.Median, Quorum: 3, Enabled: false}, s.rules.For(monitor.Pair{Base: "tia", Quote: monitor.USD}))
+
+	cfg.Strategy = "reference"
+	_, err = cfg.build("", nil)
+	assert.EqualError(t, err, "pairs[0]: subject is required by the reference strategy")
+
+	cfg.Strategy = ""
+	cfg.ThresholdMode = "ratio"
+	_, err = cfg.build("", nil)
+	assert.EqualError(t, err, `unknown threshold mode "ratio"`)
+}
+
+func TestNewNotifier_SlackRateLimit(t *testing.T) {
+	var posts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { posts++ }))
+	defer server.Close()
+
+	zero := time.Duration(0)
+	tests := []struct {
+		name      string
+		rateLimit *time.Duration
+		expected  int
+	}{
+		{name: "rate limited by default", expected: 1},
+		{name: "zero disables rate limiting", rateLimit: &zero, expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := newNotifier(NotifierConfig{Type: "slack", URL: server.URL, RateLimit: tt.rateLimit}, defaultConfig())
+			assert.NoError(t, err)
+
+			posts = 0
+			now := time.Now()
+			incident := monitor.NewIncidents(nil, nil, nil, []*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}})[0]
+			for _, at := range []time.Time{now, now.Add(time.Minute)} {
+				assert.NoError(t, n.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident, Time: at}))
+			}
+			assert.Equal(t, tt.expected, posts)
+		})
+	}
+}
+
+func TestConfig_BuildWarnings(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, `
+pairs: [osmo/usd, atom/usd]
+coins:
+  - {symbol: atom, ids: {coingecko: cosmos}}
+providers:
+  - type: coingecko
+  - type: sqs
+    base_url: http://sqs
+  - type: kraken
+    ids: {atom/usd: ATOMUSD}
+`))
+	assert.NoError(t, err)
+
+	s, err := cfg.build("", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`provider SQS skips pair atom/usd: coin atom has no identifier for provider SQS`}, s.warnings)
+
+	// Pairs providers can't price are not reported as missing every cycle.
+	osmo, atom := monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, monitor.Pair{Base: "atom", Quote: monitor.USD}
+	assert.Equal(t, map[string]monitor.Pairs{"SQS": {osmo}}, s.unexpectedMissing(map[string]monitor.Pairs{"SQS": {osmo, atom}, "Kraken": {}}))
+}
@@ -13,44 +13,54 @@ import (
 	"github.com/deividaspetraitis/price-monitor/errors"
 	ihttp "github.com/deividaspetraitis/price-monitor/http"
 	"github.com/deividaspetraitis/price-monitor/log"
-	"github.com/deividaspetraitis/price-monitor/provider"
+	"github.com/deividaspetraitis/price-monitor/notifier"
 )
 
 // shutdowntimeout is the duration the service will wait for outstanding requests to complete before shutting down.
 var shutdowntimeout = time.Duration(5) * time.Second
 
+// notificationQueueSize is the number of notifications queued per notifier before further ones are dropped.
+var notificationQueueSize = 100
+
 // Program flags
 var (
-	host        string
-	httpAddress string
-	sqsBaseURL  string
-	threshold   float64
-	interval    int
-	timeout     time.Duration
-	otel        bool
+	host       string
+	configFile string
+	coinsFile  string
+	otel       bool
 )
 
 func init() {
 	flag.StringVar(&host, "host", "price-monitor", "the name of the host")
-	flag.StringVar(&httpAddress, "http", ":8080", "HTTP service address")
-	flag.StringVar(&sqsBaseURL, "sqs-base-url", "http://localhost:9092", "SQS provider base URL")
-	flag.Float64Var(&threshold, "threshold", 0.02, "Price difference threshold for logging")
-	flag.IntVar(&interval, "interval", 60, "Interval between price checks in seconds")
-	flag.DurationVar(&timeout, "timeout", time.Second*5, "Timeout for fetching prices from each provider individually")
+	flag.StringVar(&configFile, "config", "", "Path to a YAML or JSON configuration file")
+	flag.StringVar(&coinsFile, "coins", "", "Path to a JSON file with additional coin definitions")
 	flag.BoolVar(&otel, "otel", false, "Enable OpenTelemetry")
-	flag.Parse()
-}
 
-// pairs is a list of cryptocurrency pairs to monitor.
-var pairs = monitor.Pairs{
-	{Base: monitor.OSMO, Quote: monitor.USD},
+	// Flags overriding the configuration file, their values are applied by Config.applyFlags.
+	flag.String("http", ":8080", "HTTP service address")
+	flag.String("admin-token", "", "Bearer token of the admin endpoints, e.g. /admin/reload, disabled if empty")
+	flag.String("sqs-base-url", "http://localhost:9092", "SQS provider base URL")
+	flag.Float64("threshold", 0.02, "Price difference threshold for logging")
+	flag.Int("interval", 60, "Interval between price checks in seconds")
+	flag.Duration("timeout", time.Second*5, "Deadline for fetching prices from all providers, queried concurrently")
 }
 
 // main program entry point.
 func main() {
+	flag.Parse()
+
 	ctx := context.Background()
 	logger := log.Default()
 
+	if err := setFlagsFromEnv(flag.CommandLine, os.Getenv); err != nil {
+		logger.Fatalf("Error loading configuration: %v", err)
+	}
+
+	cfg, err := readConfig()
+	if err != nil {
+		logger.Fatalf("Error loading configuration: %v", err)
+	}
+
 	if otel {
 		tp, err := monitor.NewOtelTracer(ctx, host)
 		if err != nil {
@@ -64,13 +74,33 @@ func main() {
 		}()
 	}
 
-	if err := run(ctx, logger); err != nil {
+	if err := run(ctx, cfg, logger); err != nil {
 		logger.WithError(err).Error("unable to start service")
 		os.Exit(1)
 	}
 }
 
-func run(ctx context.Context, logger log.Logger) error {
+// readConfig loads the configuration file and applies flag and environment variable overrides.
+func readConfig() (*Config, error) {
+	cfg, err := loadConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.applyFlags(flag.CommandLine); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func run(ctx context.Context, cfg *Config, logger log.Logger) error {
+	build := func(c *Config, prev *settings) (*settings, error) { return c.build(coinsFile, prev) }
+	reloader, err := newReloader(cfg, readConfig, build, logger)
+	if err != nil {
+		return errors.Wrap(err, "invalid configuration")
+	}
+
 	// Make a channel to listen for errors coming from the listener. Use a
 	// buffered channel so the goroutine can exit if we don't collect this error.
 	serverErrors := make(chan error, 1)
@@ -80,53 +110,93 @@ func run(ctx context.Context, logger log.Logger) error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
 
+	// Make a channel to listen for a hang up signal requesting configuration reload.
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+	detector := monitor.NewDetector()
+	observer := &observer{detector: detector}
+	alerts := monitor.NewAlertManager(reloader.Settings().reminderInterval)
+
+	// Notifications are delivered in the background so unreachable notification channels don't hold up monitoring.
+	dispatcher := monitor.NewDispatcher(notificationQueueSize, func(err error) {
+		logger.WithError(err).Error("unable to deliver alert notification")
+	})
+
 	// =========================================================================
 	// Start HTTP server
 
 	api := http.Server{
-		Addr:    httpAddress,
-		Handler: ihttp.API(shutdown),
+		Addr:    cfg.HTTPAddress,
+		Handler: ihttp.API(shutdown, reloader, detector, cfg.AdminToken),
 	}
 
 	go func() {
-		logger.Printf("http server listening on %s", httpAddress)
+		logger.Printf("http server listening on %s", cfg.HTTPAddress)
 		serverErrors <- api.ListenAndServe()
 	}()
 
 	// =========================================================================
 	// Start Service
 
-	// TODO: Handle shutdown gracefully
-	providers := []monitor.Provider{
-		provider.NewCoinGeckoClient(),
-		provider.NewSQSClient(sqsBaseURL),
-	}
+	// Monitoring is stopped on shutdown, cancelling the cycle in flight.
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
 
 	monitorAndLog := func() {
-		diff := monitor.Compare(monitor.Fetch(ctx, providers, pairs, timeout, logger), threshold)
-		for _, d := range diff {
-			logger.Printf(
-				"Error: Price difference for pair %s/%s exceeds threshold: %.4f > %.4f\n",
-				d.Pair.Base,
-				d.Pair.Quote,
-				d.Difference,
-				d.Threshold,
-			)
+		settings := reloader.Settings()
+		result := monitor.Fetch(ctx, settings.providers, settings.pairs, settings.timeout)
+		if ctx.Err() != nil {
+			return // providers failed as the cycle was cancelled, not worth an alert
+		}
+		for provider, pairs := range settings.unexpectedMissing(result.Missing) {
+			logger.Warnf("Provider %s returned no prices for pairs %v", provider, pairs)
+		}
+		for i, data := range result.Prices {
+			logger.Printf("%v: %v", i, data)
+		}
+
+		now := time.Now()
+		findings := monitor.Compare(result.Prices, settings.rules)
+		diffs := observer.observe(settings, findings, now)
+
+		logNotifier := notifier.NewLog(logger)
+		logNotifier.Templates = settings.templates
+		alerts.Configure(settings.reminderInterval, append([]monitor.Notifier{logNotifier}, dispatcher.Notifiers(settings.notifiers...)...)...)
+		incidents := monitor.NewIncidents(diffs, findings.Stale, findings.NoConsensus, result.Failures)
+
+		// Deliveries get until the next cycle.
+		ctx, cancel := context.WithTimeout(ctx, settings.interval)
+		defer cancel()
+		if _, err := alerts.Update(ctx, now, incidents); err != nil {
+			logger.WithError(err).Error("unable to deliver alert notifications")
 		}
 	}
 
 	// Fetch initial prices and compare them
 	monitorAndLog()
 
-	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	period := reloader.Settings().interval
+	ticker := time.NewTicker(period)
 	defer ticker.Stop()
 
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		for {
 			select {
 			case <-ticker.C:
 				monitorAndLog()
 
+				// Pick up interval changes made by a configuration reload.
+				if i := reloader.Settings().interval; i != period {
+					period = i
+					ticker.Reset(period)
+				}
+
+			case <-reload:
+				reloader.Reload() // errors are logged by the reloader
+
 			case <-ctx.Done():
 				return
 			}
@@ -155,6 +225,18 @@ func run(ctx context.Context, logger log.Logger) error {
 			api.Close()
 		}
 
+		// Stop monitoring so that no notifications are queued while they are delivered.
+		stop()
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+
+		// Deliver queued notifications and flush pending digests within what is left of the deadline.
+		if err := dispatcher.Close(ctx); err != nil {
+			logger.WithError(err).Error("undelivered alert notifications dropped")
+		}
+
 		// Log the status of this shutdown.
 		switch {
 		case sig == syscall.SIGSTOP:
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/errors"
+	"github.com/deividaspetraitis/price-monitor/log"
+)
+
+// reloader holds the active settings and atomically replaces them when the configuration is reloaded.
+// It implements ihttp.Reloader.
+type reloader struct {
+	mu       sync.Mutex // serialises reloads
+	config   *Config
+	settings atomic.Pointer[settings]
+
+	load   func() (*Config, error)                            // load reads the configuration, including overrides
+	build  func(c *Config, prev *settings) (*settings, error) // build builds the settings of c, reusing state of prev
+	logger log.Logger
+}
+
+// newReloader creates a reloader with cfg as the active configuration.
+func newReloader(cfg *Config, load func() (*Config, error), build func(*Config, *settings) (*settings, error), logger log.Logger) (*reloader, error) {
+	s, err := build(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reloader{
+		config: cfg,
+		load:   load,
+		build:  build,
+		logger: logger,
+	}
+	r.settings.Store(s)
+	r.warn(s)
+
+	return r, nil
+}
+
+// warn logs the warnings of the settings.
+func (r *reloader) warn(s *settings) {
+	for _, w := range s.warnings {
+		r.logger.Warnf("Configuration: %s", w)
+	}
+}
+
+// Settings returns the active settings.
+func (r *reloader) Settings() *settings {
+	return r.settings.Load()
+}
+
+// Reload re-reads the configuration and, if it is valid, swaps the active settings.
+// On error the active settings are kept.
+func (r *reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.reload(); err != nil {
+		r.logger.WithError(err).Error("configuration reload rejected, keeping previous configuration")
+		return err
+	}
+
+	return nil
+}
+
+func (r *reloader) reload() error {
+	cfg, err := r.load()
+	if err != nil {
+		return errors.Wrap(err, "unable to load configuration")
+	}
+
+	s, err := r.build(cfg, r.Settings())
+	if err != nil {
+		return errors.Wrap(err, "invalid configuration")
+	}
+
+	changes := diffConfig(r.config, cfg)
+	if cfg.HTTPAddress != r.config.HTTPAddress {
+		r.logger.Warnf("Configuration reload: http address change to %s requires a restart", cfg.HTTPAddress)
+	}
+	if cfg.AdminToken != r.config.AdminToken {
+		r.logger.Warnf("Configuration reload: admin token change requires a restart")
+	}
+
+	r.config = cfg
+	r.settings.Store(s)
+	r.warn(s)
+
+	if len(changes) == 0 {
+		r.logger.Infof("Configuration reloaded: no changes")
+	}
+	for _, c := range changes {
+		r.logger.Infof("Configuration reloaded: %s", c)
+	}
+
+	return nil
+}
+
+// observer records the findings of monitoring cycles with the detector. When a cycle runs with settings other than
+// the previous one, e.g. after a reload, the breaches of pairs and providers they no longer monitor are forgotten
+// first. Doing so in the cycle rather than on reload keeps a cycle still running with the previous settings from
+// bringing them back. It must be used from a single goroutine.
+type observer struct {
+	detector *monitor.Detector
+	settings *settings // settings are the settings of the previous cycle
+}
+
+// observe records the findings of a cycle run with s at now and returns the differences of active breaches.
+func (o *observer) observe(s *settings, findings monitor.Findings, now time.Time) []monitor.PriceDifference {
+	if o.settings != nil && o.settings != s {
+		s.retain(o.detector)
+	}
+	o.settings = s
+
+	return o.detector.Observe(findings.Differences, findings.Within, now)
+}
+
+// diffConfig returns a human readable list of differences between two configurations.
+func diffConfig(old, new *Config) []string {
+	var changes []string
+
+	if old.Interval != new.Interval {
+		changes = append(changes, fmt.Sprintf("interval %s -> %s", old.Interval, new.Interval))
+	}
+	if old.Timeout != new.Timeout {
+		changes = append(changes, fmt.Sprintf("timeout %s -> %s", old.Timeout, new.Timeout))
+	}
+	if old.Threshold != new.Threshold {
+		changes = append(changes, fmt.Sprintf("threshold %v -> %v", old.Threshold, new.Threshold))
+	}
+
+	if old.ThresholdMode != new.ThresholdMode {
+		changes = append(changes, fmt.Sprintf("threshold_mode %q -> %q", old.ThresholdMode, new.ThresholdMode))
+	}
+
+	if old.MinProviders != new.MinProviders {
+		changes = append(changes, fmt.Sprintf("min_providers %d -> %d", old.MinProviders, new.MinProviders))
+	}
+
+	if old.MaxAge != new.MaxAge {
+		changes = append(changes, fmt.Sprintf("max_age %s -> %s", old.MaxAge, new.MaxAge))
+	}
+
+	if old.Strategy != new.Strategy {
+		changes = append(changes, fmt.Sprintf("strategy %q -> %q", old.Strategy, new.Strategy))
+	}
+	if old.Quorum != new.Quorum {
+		changes = append(changes, fmt.Sprintf("quorum %d -> %d", old.Quorum, new.Quorum))
+	}
+
+	if old.Subject != new.Subject {
+		changes = append(changes, fmt.Sprintf("subject %q -> %q", old.Subject, new.Subject))
+	}
+	if !slices.Equal(old.References, new.References) {
+		changes = append(changes, fmt.Sprintf("references %v -> %v", old.References, new.References))
+	}
+
+	if old.ConfidenceTolerance != new.ConfidenceTolerance {
+		changes = append(changes, fmt.Sprintf("confidence_tolerance %t -> %t", old.ConfidenceTolerance, new.ConfidenceTolerance))
+	}
+
+	if old.RaiseAfter != new.RaiseAfter {
+		changes = append(changes, fmt.Sprintf("raise_after %d -> %d", old.RaiseAfter, new.RaiseAfter))
+	}
+	if old.RaiseAfterDuration != new.RaiseAfterDuration {
+		changes = append(changes, fmt.Sprintf("raise_after_duration %s -> %s", old.RaiseAfterDuration, new.RaiseAfterDuration))
+	}
+	if old.ClearAfter != new.ClearAfter {
+		changes = append(changes, fmt.Sprintf("clear_after %d -> %d", old.ClearAfter, new.ClearAfter))
+	}
+
+	if old.Alerts.ReminderInterval != new.Alerts.ReminderInterval {
+		changes = append(changes, fmt.Sprintf("alerts reminder_interval %s -> %s", old.Alerts.ReminderInterval, new.Alerts.ReminderInterval))
+	}
+
+	if old.Alerts.MetricsURL != new.Alerts.MetricsURL {
+		changes = append(changes, fmt.Sprintf("alerts metrics_url %q -> %q", old.Alerts.MetricsURL, new.Alerts.MetricsURL))
+	}
+	if !reflect.DeepEqual(old.Alerts.Templates, new.Alerts.Templates) {
+		changes = append(changes, "alerts templates changed")
+	}
+	if !reflect.DeepEqual(old.Alerts.Notifiers, new.Alerts.Notifiers) {
+		// Notifier URLs may embed credentials, only types are logged.
+		notifiers := func(c *Config) []string {
+			var s []string
+			for _, n := range c.Alerts.Notifiers {
+				if n.IsEnabled() {
+					s = append(s, n.Type)
+				}
+			}
+			return s
+		}
+		changes = append(changes, fmt.Sprintf("alerts notifiers %v -> %v", notifiers(old), notifiers(new)))
+	}
+
+	pairs := func(c *Config) []string {
+		var s []string
+		for _, p := range c.Pairs {
+			s = append(s, p.String())
+		}
+		return s
+	}
+	added, removed := diffStrings(pairs(old), pairs(new))
+	for _, p := range added {
+		changes = append(changes, "pair added "+p)
+	}
+	for _, p := range removed {
+		changes = append(changes, "pair removed "+p)
+	}
+
+	coins := func(c *Config) []string {
+		var s []string
+		for _, coin := range c.Coins {
+			s = append(s, fmt.Sprintf("%+v", coin))
+		}
+		return s
+	}
+	added, removed = diffStrings(coins(old), coins(new))
+	for _, c := range added {
+		changes = append(changes, "coin added "+c)
+	}
+	for _, c := range removed {
+		changes = append(changes, "coin removed "+c)
+	}
+
+	if !reflect.DeepEqual(old.Providers, new.Providers) {
+		providers := func(c *Config) []string {
+			var s []string
+			for _, p := range c.Providers {
+				desc := fmt.Sprintf("%s(enabled=%t base_url=%s timeout=%s", p.Type, p.IsEnabled(), redactURL(p.BaseURL), p.Timeout)
+				if len(p.IDs) > 0 {
+					desc += fmt.Sprintf(" ids=%v", p.IDs)
+				}
+				if p.Name != "" {
+					desc += fmt.Sprintf(" name=%s url=%s path=%s scale=%v", p.Name, redactURL(p.URL), p.Path, p.Scale)
+				}
+				if len(p.Headers) > 0 {
+					desc += fmt.Sprintf(" headers=%v", slices.Sorted(maps.Keys(p.Headers))) // values may be credentials
+				}
+				s = append(s, desc+")")
+			}
+			return s
+		}
+		added, removed = diffStrings(providers(old), providers(new))
+		for _, p := range added {
+			changes = append(changes, "provider added "+p)
+		}
+		for _, p := range removed {
+			changes = append(changes, "provider removed "+p)
+		}
+	}
+
+	return changes
+}
+
+// redactURL returns the scheme and host of the URL s only, as its user info, path or query may carry
+// credentials, e.g. an API key. An invalid URL is redacted entirely.
+func redactURL(s string) string {
+	if s == "" {
+		return ""
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return "<redacted>"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// diffStrings returns the elements present only in new and only in old, preserving their order.
+func diffStrings(old, new []string) (added, removed []string) {
+	for _, v := range new {
+		if !slices.Contains(old, v) {
+			added = append(added, v)
+		}
+	}
+	for _, v := range old {
+		if !slices.Contains(new, v) {
+			removed = append(removed, v)
+		}
+	}
+
+	return added, removed
+}
@@ -1,23 +1,34 @@
 package monitor
 
-// List of supported cryptocurrencies.
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/deividaspetraitis/price-monitor/errors"
+)
+
+// List of built-in cryptocurrencies, always present in the DefaultRegistry.
 const (
-	OSMO Coin = iota
-	USD
+	OSMO Coin = "osmo"
+	USD  Coin = "usd"
 )
 
-// Coin represents a cryptocurrency.
-type Coin int
+// ErrUnknownCoin is returned when a coin is not present in the registry.
+var ErrUnknownCoin = errors.New("unknown coin")
+
+// ErrNoIdentifier is returned when a coin has no identifier for a provider.
+var ErrNoIdentifier = errors.New("no identifier for provider")
+
+// Coin represents a cryptocurrency identified by its symbol.
+type Coin string
 
 // String returns the string representation of the Coin.
 func (c Coin) String() string {
-	switch c {
-	case OSMO:
-		return "osmo"
-	case USD:
-		return "usd"
-	}
-	return ""
+	return string(c)
 }
 
 // Pair represents a cryptocurrency pair.
@@ -26,5 +37,186 @@ type Pair struct {
 	Quote Coin // Quote Coin
 }
 
+// String returns the string representation of the Pair in the form base/quote.
+func (p Pair) String() string {
+	return p.Base.String() + "/" + p.Quote.String()
+}
+
+// ParsePair parses a pair in the form base/quote.
+func ParsePair(s string) (Pair, error) {
+	base, quote, ok := strings.Cut(s, "/")
+	if !ok || base == "" || quote == "" {
+		return Pair{}, errors.Newf("invalid pair %q, expected base/quote", s)
+	}
+	return Pair{Base: Coin(strings.ToLower(base)), Quote: Coin(strings.ToLower(quote))}, nil
+}
+
 // Pairs is a list of cryptocurrency pairs.
 type Pairs []Pair
+
+// CoinInfo describes a cryptocurrency known to the monitor.
+type CoinInfo struct {
+	Symbol   Coin              `json:"symbol" yaml:"symbol"`     // Symbol is the canonical, lower case, symbol of the coin
+	Name     string            `json:"name" yaml:"name"`         // Name is the human readable display name
+	Decimals int               `json:"decimals" yaml:"decimals"` // Decimals is the number of decimals of the smallest unit
+	IDs      map[string]string `json:"ids" yaml:"ids"`           // IDs maps provider names to provider specific identifiers
+}
+
+// Registry holds the set of known coins and their provider identifiers.
+// It is safe for concurrent use.
+type Registry struct {
+	mu    sync.RWMutex
+	coins map[Coin]CoinInfo
+}
+
+// NewRegistry creates a new Registry populated with the given coins.
+func NewRegistry(coins ...CoinInfo) (*Registry, error) {
+	r := &Registry{coins: make(map[Coin]CoinInfo, len(coins))}
+	for _, c := range coins {
+		if err := r.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// DefaultCoins is the list of coins the monitor knows about out of the box.
+var DefaultCoins = []CoinInfo{
+	{
+		Symbol:   OSMO,
+		Name:     "Osmosis",
+		Decimals: 6,
+		IDs: map[string]string{
+			"coingecko": "osmosis",
+			"sqs":       "uosmo",
+			"osmosis":   "uosmo",
+			"binance":   "OSMO",
+			"kraken":    "OSMO",
+			"coinbase":  "OSMO",
+		},
+	},
+	{
+		Symbol:   USD,
+		Name:     "US Dollar",
+		Decimals: 6,
+		IDs: map[string]string{
+			"coingecko": "usd",
+			"sqs":       "ibc/498A0751C798A0D9A389AA3691123DADA57DAA4FE165D5C75894505B876BA6E4", // USDC
+			"osmosis":   "ibc/498A0751C798A0D9A389AA3691123DADA57DAA4FE165D5C75894505B876BA6E4",
+			"binance":   "USDT",
+			"kraken":    "USD",
+			"coinbase":  "USD",
+		},
+	},
+}
+
+// DefaultRegistry returns a new Registry populated with DefaultCoins.
+func DefaultRegistry() *Registry {
+	r, err := NewRegistry(DefaultCoins...)
+	if err != nil {
+		panic(err) // DefaultCoins are static, this is a programming error
+	}
+	return r
+}
+
+// ReadRegistry decodes a JSON list of CoinInfo from r and registers them on top of DefaultCoins.
+func ReadRegistry(r io.Reader) (*Registry, error) {
+	reg := DefaultRegistry()
+	if err := reg.Load(r); err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// Load decodes a JSON list of CoinInfo from r and registers each of them.
+func (r *Registry) Load(rd io.Reader) error {
+	var coins []CoinInfo
+	if err := json.NewDecoder(rd).Decode(&coins); err != nil {
+		return errors.Wrap(err, "unable to decode coins")
+	}
+
+	for _, c := range coins {
+		if err := r.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Register adds or replaces a coin in the registry.
+// Provider names in IDs are matched case-insensitively.
+func (r *Registry) Register(info CoinInfo) error {
+	if info.Symbol == "" {
+		return errors.New("coin symbol is required")
+	}
+	if info.Decimals < 0 {
+		return errors.Newf("coin %s: decimals must not be negative", info.Symbol)
+	}
+
+	info.Symbol = Coin(strings.ToLower(string(info.Symbol)))
+	ids := make(map[string]string, len(info.IDs))
+	for provider, id := range info.IDs {
+		ids[strings.ToLower(provider)] = id
+	}
+	info.IDs = ids
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.coins[info.Symbol] = info
+
+	return nil
+}
+
+// Lookup returns the CoinInfo registered for the given coin.
+func (r *Registry) Lookup(c Coin) (CoinInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	info, ok := r.coins[c]
+	if !ok {
+		return CoinInfo{}, errors.Wrapf(ErrUnknownCoin, "%q", c)
+	}
+	return info, nil
+}
+
+// ID returns the identifier of the coin used by the given provider.
+func (r *Registry) ID(c Coin, provider string) (string, error) {
+	info, err := r.Lookup(c)
+	if err != nil {
+		return "", err
+	}
+
+	id, ok := info.IDs[strings.ToLower(provider)]
+	if !ok || id == "" {
+		return "", fmt.Errorf("coin %s has %w %s", c, ErrNoIdentifier, provider)
+	}
+	return id, nil
+}
+
+// Coins returns all registered coins sorted by symbol.
+func (r *Registry) Coins() []CoinInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	coins := make([]CoinInfo, 0, len(r.coins))
+	for _, c := range r.coins {
+		coins = append(coins, c)
+	}
+	sort.Slice(coins, func(i, j int) bool { return coins[i].Symbol < coins[j].Symbol })
+
+	return coins
+}
+
+// Validate checks that every coin of every pair is registered.
+func (r *Registry) Validate(pairs Pairs) error {
+	for _, p := range pairs {
+		if _, err := r.Lookup(p.Base); err != nil {
+			return errors.Wrapf(err, "pair %s", p)
+		}
+		if _, err := r.Lookup(p.Quote); err != nil {
+			return errors.Wrapf(err, "pair %s", p)
+		}
+	}
+	return nil
+}
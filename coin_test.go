@@ -0,0 +1,89 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deividaspetraitis/price-monitor/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_ID(t *testing.T) {
+	tests := []struct {
+		name          string
+		coin          Coin
+		provider      string
+		expectedID    string
+		expectedError string
+	}{
+		{
+			name:       "known coin",
+			coin:       OSMO,
+			provider:   "CoinGecko",
+			expectedID: "osmosis",
+		},
+		{
+			name:       "provider name is case insensitive",
+			coin:       OSMO,
+			provider:   "sqs",
+			expectedID: "uosmo",
+		},
+		{
+			name:          "unknown coin",
+			coin:          "doge",
+			provider:      "CoinGecko",
+			expectedError: "unknown coin",
+		},
+		{
+			name:          "unknown provider",
+			coin:          OSMO,
+			provider:      "Bitstamp",
+			expectedError: "has no identifier for provider Bitstamp",
+		},
+	}
+
+	registry := DefaultRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := registry.ID(tt.coin, tt.provider)
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedID, id)
+			}
+		})
+	}
+}
+
+func TestReadRegistry(t *testing.T) {
+	registry, err := ReadRegistry(strings.NewReader(`[
+		{"symbol": "ATOM", "name": "Cosmos Hub", "decimals": 6, "ids": {"CoinGecko": "cosmos", "sqs": "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"}}
+	]`))
+	assert.NoError(t, err)
+
+	id, err := registry.ID("atom", "coingecko")
+	assert.NoError(t, err)
+	assert.Equal(t, "cosmos", id)
+
+	// Built-in coins are still available.
+	_, err = registry.Lookup(OSMO)
+	assert.NoError(t, err)
+
+	err = registry.Validate(Pairs{{Base: "atom", Quote: USD}, {Base: "tia", Quote: USD}})
+	assert.True(t, errors.Is(err, ErrUnknownCoin))
+
+	_, err = registry.ID("atom", "binance")
+	assert.True(t, errors.Is(err, ErrNoIdentifier))
+}
+
+func TestParsePair(t *testing.T) {
+	p, err := ParsePair("OSMO/usd")
+	assert.NoError(t, err)
+	assert.Equal(t, Pair{Base: OSMO, Quote: USD}, p)
+	assert.Equal(t, "osmo/usd", p.String())
+
+	_, err = ParsePair("osmo")
+	assert.Error(t, err)
+}
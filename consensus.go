@@ -0,0 +1,100 @@
+package monitor
+
+import (
+	"sort"
+)
+
+// Consensus is the service name reported as PriceDifference.ServiceB when a price is compared with the consensus price.
+const Consensus = "consensus"
+
+// NoConsensus holds details about a pair for which too few providers answered to establish consensus.
+type NoConsensus struct {
+	Pair      Pair
+	Providers []string // Providers are the providers which answered
+	Quorum    int      // Quorum is the number of answers required
+	Rule      Rule
+}
+
+// newNoConsensus creates a NoConsensus finding for the pair from the prices that were received.
+func newNoConsensus(pair Pair, ps []PriceData, rule Rule) NoConsensus {
+	providers := make([]string, len(ps))
+	for i, p := range ps {
+		providers[i] = p.Service
+	}
+	return NoConsensus{Pair: pair, Providers: providers, Quorum: rule.Quorum, Rule: rule}
+}
+
+// compareConsensus compares every price of the pair with the consensus price and returns the
+// providers deviating from it by more than the rule threshold and the comparisons within it.
+// The deviation is relative to the consensus price, which has no confidence interval of its own.
+func compareConsensus(pair Pair, ps []PriceData, rule Rule) (diffs, within []PriceDifference) {
+	values := make([]float64, len(ps))
+	for i, p := range ps {
+		values[i] = p.Price
+	}
+
+	var consensus float64
+	switch rule.Strategy {
+	case TrimmedMean:
+		consensus = trimmedMean(values)
+	default:
+		consensus = median(values)
+	}
+
+	for _, p := range ps {
+		diff, rel := deviation(p.Price, consensus, consensus)
+		d := PriceDifference{
+			Pair:        pair,
+			ServiceA:    p.Service,
+			ServiceB:    Consensus,
+			PriceA:      p.Price,
+			PriceB:      consensus,
+			ObservedAtA: p.ObservedAt,
+			Difference:  diff,
+			Relative:    rel,
+			Direction:   direction(p.Price, consensus),
+			Rule:        rule,
+		}
+		if rule.exceededBeyond(diff, rel, p.Confidence) {
+			diffs = append(diffs, d)
+		} else {
+			within = append(within, d)
+		}
+	}
+	return diffs, within
+}
+
+// median returns the median of values.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// trimmedMean returns the mean of values without the lowest and the highest value.
+// With fewer than three values it is the plain mean.
+func trimmedMean(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	if len(sorted) >= 3 {
+		sorted = sorted[1 : len(sorted)-1]
+	}
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	return sum / float64(len(sorted))
+}
@@ -0,0 +1,106 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare_Consensus(t *testing.T) {
+	osmo := Pair{Base: OSMO, Quote: USD}
+	atom := Pair{Base: "atom", Quote: USD}
+
+	tests := []struct {
+		name     string
+		prices   []PriceData
+		rules    Rules
+		expected Findings
+	}{
+		{
+			name: "median identifies the outlier",
+			prices: []PriceData{
+				{Pair: osmo, Service: "Provider1", Price: 0.20},
+				{Pair: osmo, Service: "Provider2", Price: 0.25},
+				{Pair: osmo, Service: "Provider3", Price: 0.201},
+			},
+			rules: Rules{Default: Rule{Threshold: 5, Mode: Percent, Strategy: Median, Enabled: true}},
+			expected: Findings{Differences: []PriceDifference{
+				{
+					Pair:       osmo,
+					ServiceA:   "Provider2",
+					ServiceB:   Consensus,
+					PriceA:     0.25,
+					PriceB:     0.201,
+					Difference: 0.04899999999999999,
+					Relative:   0.2437810945273631,
+					Direction:  Over,
+					Rule:       Rule{Threshold: 5, Mode: Percent, Strategy: Median, Enabled: true},
+				},
+			}},
+		},
+		{
+			name: "trimmed mean",
+			prices: []PriceData{
+				{Pair: osmo, Service: "Provider1", Price: 1},
+				{Pair: osmo, Service: "Provider2", Price: 2},
+				{Pair: osmo, Service: "Provider3", Price: 3},
+				{Pair: osmo, Service: "Provider4", Price: 100},
+			},
+			rules: Rules{Default: Rule{Threshold: 50, Strategy: TrimmedMean, Enabled: true}},
+			expected: Findings{Differences: []PriceDifference{
+				{
+					Pair:       osmo,
+					ServiceA:   "Provider4",
+					ServiceB:   Consensus,
+					PriceA:     100,
+					PriceB:     2.5,
+					Difference: 97.5,
+					Relative:   39,
+					Direction:  Over,
+					Rule:       Rule{Threshold: 50, Strategy: TrimmedMean, Enabled: true},
+				},
+			}},
+		},
+		{
+			name: "no consensus",
+			prices: []PriceData{
+				{Pair: osmo, Service: "Provider1", Price: 0.20},
+				{Pair: osmo, Service: "Provider2", Price: 0.30},
+			},
+			rules: Rules{
+				Default: Rule{Threshold: 0.01, Strategy: Median, Quorum: 3, Enabled: true},
+				Pairs: map[Pair]Rule{
+					atom: {Threshold: 0.01, Strategy: Median, Quorum: 2, Enabled: true},
+				},
+			},
+			expected: Findings{NoConsensus: []NoConsensus{
+				{
+					Pair:      osmo,
+					Providers: []string{"Provider1", "Provider2"},
+					Quorum:    3,
+					Rule:      Rule{Threshold: 0.01, Strategy: Median, Quorum: 3, Enabled: true},
+				},
+				{
+					Pair:      atom,
+					Providers: []string{},
+					Quorum:    2,
+					Rule:      Rule{Threshold: 0.01, Strategy: Median, Quorum: 2, Enabled: true},
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Compare(tt.prices, tt.rules)
+			result.Within = nil // covered by TestCompare_Within
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, 0.0, median(nil))
+	assert.Equal(t, 2.0, median([]float64{3, 1, 2}))
+	assert.Equal(t, 2.5, median([]float64{4, 1, 3, 2}))
+}
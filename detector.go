@@ -0,0 +1,170 @@
+package monitor
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Breach is the state of a pair and providers combination whose prices exceed the rule threshold.
+type Breach struct {
+	Key         string          // Key identifies the pair and providers
+	Since       time.Time       // Since is the time of the first breaching cycle
+	LastSeen    time.Time       // LastSeen is the time of the last breaching cycle
+	Consecutive int             // Consecutive is the number of consecutive breaching cycles
+	Healthy     int             // Healthy is the number of healthy cycles of an active breach since it was last observed
+	Missed      int             // Missed is the number of consecutive cycles in which the pair was not compared at all
+	Active      bool            // Active indicates whether the breach persisted long enough to be raised
+	Difference  PriceDifference // Difference is the last observed difference
+}
+
+// Duration returns for how long the breach has been observed.
+func (b Breach) Duration() time.Duration {
+	return b.LastSeen.Sub(b.Since)
+}
+
+// breachKey returns the key identifying the pair and providers of d.
+func breachKey(d PriceDifference) string {
+	return d.Pair.String() + "|" + d.ServiceA + "|" + d.ServiceB
+}
+
+// expireAfter is the number of consecutive cycles without any comparison of its pair after which a breach is forgotten.
+const expireAfter = 10
+
+// Detector tracks price differences across cycles and raises only those persisting long enough.
+// A difference is raised once it was observed for Rule.RaiseAfter consecutive cycles or for Rule.RaiseAfterDuration,
+// and cleared once it was healthy for Rule.ClearAfter cycles in a row. A cycle is healthy if the providers were
+// compared within the threshold, or if the pair was compared but the providers were not, e.g. as one is down or
+// the subject fell back to another reference. Cycles in which the pair was not compared at all, e.g. as too few
+// prices are fresh, neither raise nor clear a difference, but after 10 such cycles in a row it is forgotten.
+// Retain forgets differences whose providers won't be compared anymore.
+// It is safe for concurrent use.
+type Detector struct {
+	mu       sync.Mutex
+	breaches map[string]*Breach
+}
+
+// NewDetector creates a new Detector.
+func NewDetector() *Detector {
+	return &Detector{breaches: make(map[string]*Breach)}
+}
+
+// Observe records the differences and the comparisons within the threshold of a cycle observed at now and returns
+// the differences of active breaches, including breaches which are healthy or not compared but not cleared yet,
+// in the order of diffs followed by the key order.
+func (d *Detector) Observe(diffs, within []PriceDifference, now time.Time) []PriceDifference {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	healthy := make(map[string]bool, len(within))
+	compared := make(map[Pair]bool)
+	for _, w := range within {
+		healthy[breachKey(w)] = true
+		compared[w.Pair] = true
+	}
+	for _, diff := range diffs {
+		compared[diff.Pair] = true
+	}
+
+	seen := make(map[string]bool, len(diffs))
+	for _, diff := range diffs {
+		key := breachKey(diff)
+		seen[key] = true
+
+		b, ok := d.breaches[key]
+		if !ok {
+			b = &Breach{Key: key, Since: now}
+			d.breaches[key] = b
+		}
+		b.LastSeen = now
+		b.Consecutive++
+		b.Healthy = 0
+		b.Missed = 0
+		b.Difference = diff
+
+		if !b.Active && diff.Rule.persisted(b.Consecutive, b.Duration()) {
+			b.Active = true
+		}
+	}
+
+	for _, key := range d.keys() {
+		if seen[key] {
+			continue
+		}
+
+		b := d.breaches[key]
+		if !healthy[key] && !compared[b.Difference.Pair] {
+			b.Missed++
+			if b.Missed >= expireAfter {
+				delete(d.breaches, key)
+			}
+			continue
+		}
+		b.Missed = 0
+		if !b.Active {
+			delete(d.breaches, key)
+			continue
+		}
+
+		b.Healthy++
+		if b.Healthy >= max(b.Difference.Rule.ClearAfter, 1) {
+			delete(d.breaches, key)
+		}
+	}
+
+	var active []PriceDifference
+	for _, diff := range diffs {
+		if b := d.breaches[breachKey(diff)]; b.Active {
+			active = append(active, diff)
+			PricingErrorCounter.WithLabelValues(diff.Pair.String(), diff.ServiceA, diff.ServiceB).Inc() // Increment error counter
+		}
+	}
+	for _, key := range d.keys() {
+		if b := d.breaches[key]; !seen[key] && b.Active {
+			active = append(active, b.Difference)
+		}
+	}
+
+	return active
+}
+
+// Retain forgets the breaches, pending and active, whose providers are no longer compared: breaches of pairs
+// not in pairs or with a disabled rule, of providers not in providers and of comparisons the pair rule doesn't
+// make, e.g. after its strategy changed. It is meant to be called when the monitored pairs, providers or rules change,
+// as such breaches would otherwise neither raise nor clear anymore.
+func (d *Detector) Retain(pairs Pairs, providers []string, rules Rules) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key, b := range d.breaches {
+		diff := b.Difference
+		rule := rules.For(diff.Pair)
+		if !slices.Contains(pairs, diff.Pair) || !rule.Enabled || !rule.compares(diff.ServiceA, diff.ServiceB) ||
+			!slices.Contains(providers, diff.ServiceA) || (diff.ServiceB != Consensus && !slices.Contains(providers, diff.ServiceB)) {
+			delete(d.breaches, key)
+		}
+	}
+}
+
+// Breaches returns a snapshot of the current breaches, pending and active, sorted by key.
+func (d *Detector) Breaches() []Breach {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	breaches := make([]Breach, 0, len(d.breaches))
+	for _, key := range d.keys() {
+		breaches = append(breaches, *d.breaches[key])
+	}
+	return breaches
+}
+
+// keys returns the sorted keys of the breaches. The caller must hold the lock.
+func (d *Detector) keys() []string {
+	keys := make([]string, 0, len(d.breaches))
+	for key := range d.breaches {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
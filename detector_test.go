@@ -0,0 +1,243 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetector_Observe(t *testing.T) {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	rule := Rule{Threshold: 0.01, Enabled: true, RaiseAfter: 3, ClearAfter: 2}
+	diff := PriceDifference{Pair: Pair{Base: OSMO, Quote: USD}, ServiceA: "SQS", ServiceB: "CoinGecko", PriceA: 0.2, PriceB: 0.1, Rule: rule}
+
+	d := NewDetector()
+	within := diff
+	within.PriceA = within.PriceB
+	cycle := func(i int, diffs ...PriceDifference) []PriceDifference {
+		return d.Observe(diffs, nil, start.Add(time.Duration(i)*time.Minute))
+	}
+	healthy := func(i int) []PriceDifference {
+		return d.Observe(nil, []PriceDifference{within}, start.Add(time.Duration(i)*time.Minute))
+	}
+
+	// A single noisy tick is not raised, kept while the providers are not compared and forgotten on the next healthy cycle.
+	assert.Empty(t, cycle(0, diff))
+	assert.Len(t, d.Breaches(), 1)
+	assert.Empty(t, cycle(1))
+	assert.Len(t, d.Breaches(), 1)
+	assert.Empty(t, healthy(1))
+	assert.Empty(t, d.Breaches())
+
+	// The difference is raised on the third consecutive cycle.
+	assert.Empty(t, cycle(2, diff))
+	assert.Empty(t, cycle(3, diff))
+	assert.Equal(t, []PriceDifference{diff}, cycle(4, diff))
+
+	breaches := d.Breaches()
+	assert.Len(t, breaches, 1)
+	assert.True(t, breaches[0].Active)
+	assert.Equal(t, 3, breaches[0].Consecutive)
+	assert.Equal(t, 2*time.Minute, breaches[0].Duration())
+
+	// Cycles without a comparison of the pair, e.g. as its prices are stale, don't clear it.
+	for i := 5; i < 10; i++ {
+		assert.Equal(t, []PriceDifference{diff}, cycle(i))
+	}
+	assert.Equal(t, 0, d.Breaches()[0].Healthy)
+
+	// It stays raised for one healthy cycle and is cleared on the second.
+	assert.Equal(t, []PriceDifference{diff}, healthy(10))
+	assert.Equal(t, 1, d.Breaches()[0].Healthy)
+	assert.Empty(t, healthy(11))
+	assert.Empty(t, d.Breaches())
+}
+
+func TestDetector_RaiseAfterDuration(t *testing.T) {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	rule := Rule{Threshold: 0.01, Enabled: true, RaiseAfter: 100, RaiseAfterDuration: 5 * time.Minute}
+	diff := PriceDifference{Pair: Pair{Base: OSMO, Quote: USD}, ServiceA: "SQS", ServiceB: "CoinGecko", Rule: rule}
+
+	d := NewDetector()
+	assert.Empty(t, d.Observe([]PriceDifference{diff}, nil, start))
+	assert.Empty(t, d.Observe([]PriceDifference{diff}, nil, start.Add(4*time.Minute)))
+	assert.Len(t, d.Observe([]PriceDifference{diff}, nil, start.Add(5*time.Minute)), 1)
+}
+
+func TestDetector_RaiseImmediately(t *testing.T) {
+	diff := PriceDifference{Pair: Pair{Base: OSMO, Quote: USD}, ServiceA: "SQS", ServiceB: "CoinGecko", Rule: Rule{Enabled: true}}
+
+	d := NewDetector()
+	assert.Len(t, d.Observe([]PriceDifference{diff}, nil, time.Now()), 1)
+	assert.Empty(t, d.Observe(nil, []PriceDifference{diff}, time.Now()))
+}
+
+func TestDetector_Expire(t *testing.T) {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	diff := PriceDifference{Pair: Pair{Base: OSMO, Quote: USD}, ServiceA: "SQS", ServiceB: "CoinGecko", Rule: Rule{Enabled: true}}
+
+	d := NewDetector()
+	assert.Len(t, d.Observe([]PriceDifference{diff}, nil, start), 1)
+
+	// An active breach whose pair is not compared anymore is reported until it expires.
+	for i := 1; i < expireAfter; i++ {
+		assert.Len(t, d.Observe(nil, nil, start.Add(time.Duration(i)*time.Minute)), 1)
+		assert.Equal(t, i, d.Breaches()[0].Missed)
+	}
+	assert.Empty(t, d.Observe(nil, nil, start.Add(expireAfter*time.Minute)))
+	assert.Empty(t, d.Breaches())
+}
+
+func TestDetector_PairCompared(t *testing.T) {
+	osmo := Pair{Base: OSMO, Quote: USD}
+	rule := Rule{Enabled: true, ClearAfter: 2}
+	diff := PriceDifference{Pair: osmo, ServiceA: "SQS", ServiceB: "CoinGecko", Rule: rule}
+	other := PriceDifference{Pair: osmo, ServiceA: "SQS", ServiceB: "Binance", Rule: rule}
+
+	d := NewDetector()
+	assert.Len(t, d.Observe([]PriceDifference{diff}, nil, time.Now()), 1)
+
+	// The pair is compared without the providers of the breach, e.g. as CoinGecko is down, the cycles count as healthy.
+	assert.Equal(t, []PriceDifference{diff}, d.Observe(nil, []PriceDifference{other}, time.Now()))
+	assert.Equal(t, 1, d.Breaches()[0].Healthy)
+	assert.Empty(t, d.Observe(nil, []PriceDifference{other}, time.Now()))
+	assert.Empty(t, d.Breaches())
+}
+
+func TestDetector_ReferenceFallback(t *testing.T) {
+	osmo := Pair{Base: OSMO, Quote: USD}
+	rules := Rules{Default: Rule{Threshold: 1, Mode: Percent, Strategy: Reference, Subject: "SQS", References: []string{"CoinGecko", "Binance"}, Enabled: true}}
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	d := NewDetector()
+	m := NewAlertManager(0)
+	cycle := func(i int, prices ...PriceData) []Event {
+		now := start.Add(time.Duration(i) * time.Minute)
+		findings := Compare(prices, rules)
+		events, err := m.Update(context.Background(), now, NewIncidents(d.Observe(findings.Differences, findings.Within, now), nil, nil, nil))
+		assert.NoError(t, err)
+		return events
+	}
+
+	// CoinGecko is missing, SQS deviates from the fallback reference.
+	events := cycle(0, PriceData{Pair: osmo, Service: "SQS", Price: 1}, PriceData{Pair: osmo, Service: "Binance", Price: 2})
+	assert.Len(t, events, 1)
+	assert.Equal(t, Firing, events[0].Type)
+	assert.Equal(t, "Binance", events[0].Incident.Difference.ServiceB)
+
+	// CoinGecko is back and every price agrees, the difference from the fallback reference resolves.
+	events = cycle(1,
+		PriceData{Pair: osmo, Service: "SQS", Price: 1},
+		PriceData{Pair: osmo, Service: "CoinGecko", Price: 1},
+		PriceData{Pair: osmo, Service: "Binance", Price: 1},
+	)
+	assert.Len(t, events, 1)
+	assert.Equal(t, Resolved, events[0].Type)
+	assert.Empty(t, d.Breaches())
+}
+
+func TestDetector_Retain(t *testing.T) {
+	osmo := Pair{Base: OSMO, Quote: USD}
+	atom := Pair{Base: "atom", Quote: USD}
+	rule := Rule{Threshold: 0.01, Enabled: true}
+	providers := []string{"SQS", "CoinGecko", "Binance"}
+	active := PriceDifference{Pair: osmo, ServiceA: "SQS", ServiceB: "CoinGecko", Rule: rule}
+	pending := PriceDifference{Pair: osmo, ServiceA: "SQS", ServiceB: "Binance", Rule: Rule{Threshold: 0.01, Enabled: true, RaiseAfter: 3}}
+
+	tests := []struct {
+		name      string
+		pairs     Pairs
+		providers []string
+		rules     Rules
+		expected  int
+	}{
+		{
+			name:      "unchanged configuration keeps breaches",
+			pairs:     Pairs{osmo},
+			providers: providers,
+			rules:     Rules{Default: rule},
+			expected:  2,
+		},
+		{
+			name:      "removed pair",
+			pairs:     Pairs{atom},
+			providers: providers,
+			rules:     Rules{Default: rule},
+			expected:  0,
+		},
+		{
+			name:      "removed provider",
+			pairs:     Pairs{osmo},
+			providers: []string{"SQS", "CoinGecko"},
+			rules:     Rules{Default: rule},
+			expected:  1,
+		},
+		{
+			name:      "disabled rule",
+			pairs:     Pairs{osmo},
+			providers: providers,
+			rules:     Rules{Default: rule, Pairs: map[Pair]Rule{osmo: {}}},
+			expected:  0,
+		},
+		{
+			name:      "consensus strategy no longer compares providers with each other",
+			pairs:     Pairs{osmo},
+			providers: providers,
+			rules:     Rules{Default: Rule{Threshold: 0.01, Enabled: true, Strategy: Median}},
+			expected:  0,
+		},
+		{
+			name:      "reference strategy compares the subject with every other provider",
+			pairs:     Pairs{osmo},
+			providers: providers,
+			rules:     Rules{Default: Rule{Threshold: 0.01, Enabled: true, Strategy: Reference, Subject: "sqs"}},
+			expected:  2,
+		},
+		{
+			name:      "reference strategy compares the subject with its reference only",
+			pairs:     Pairs{osmo},
+			providers: providers,
+			rules:     Rules{Default: Rule{Threshold: 0.01, Enabled: true, Strategy: Reference, Subject: "SQS", References: []string{"Binance"}}},
+			expected:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector()
+			assert.Equal(t, []PriceDifference{active}, d.Observe([]PriceDifference{active, pending}, nil, time.Now()))
+
+			d.Retain(tt.pairs, tt.providers, tt.rules)
+			assert.Len(t, d.Breaches(), tt.expected)
+		})
+	}
+}
+
+func TestDetector_RetainResolvesIncident(t *testing.T) {
+	now := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	diff := PriceDifference{Pair: Pair{Base: OSMO, Quote: USD}, ServiceA: "SQS", ServiceB: "CoinGecko", Rule: Rule{Threshold: 0.01, Enabled: true}}
+
+	d := NewDetector()
+	m := NewAlertManager(time.Minute)
+	cycle := func(diffs ...PriceDifference) []Event {
+		now = now.Add(time.Minute)
+		events, err := m.Update(context.Background(), now, NewIncidents(d.Observe(diffs, nil, now), nil, nil, nil))
+		assert.NoError(t, err)
+		return events
+	}
+
+	events := cycle(diff)
+	assert.Len(t, events, 1)
+	assert.Equal(t, Firing, events[0].Type)
+
+	// The pair is removed, its providers are no longer compared.
+	d.Retain(nil, []string{"SQS", "CoinGecko"}, Rules{Default: diff.Rule})
+	assert.Empty(t, d.Breaches())
+
+	events = cycle()
+	assert.Len(t, events, 1)
+	assert.Equal(t, Resolved, events[0].Type)
+	assert.Empty(t, cycle())
+}
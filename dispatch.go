@@ -0,0 +1,208 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor/errors"
+)
+
+// ErrQueueFull is returned by queued notifiers which can't take more notifications.
+var ErrQueueFull = errors.New("notification queue is full")
+
+// ErrQueueClosed is returned by queued notifiers once their Dispatcher stopped delivering.
+var ErrQueueClosed = errors.New("notification queue is closed")
+
+// Dispatcher delivers notifications in the background, in a queue per notifier, so a slow or unreachable
+// notification channel neither blocks the caller nor delays the other notifiers. It is safe for concurrent use.
+type Dispatcher struct {
+	size    int
+	onError func(error)
+
+	ctx    context.Context // ctx is the parent of deliveries, cancelled when closing gives up on them
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	queues map[Notifier]*queue
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a new Dispatcher queueing up to size notifications per notifier.
+// onError is called with the errors of notifiers which failed to deliver.
+func NewDispatcher(size int, onError func(error)) *Dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		size:    size,
+		onError: onError,
+		ctx:     ctx,
+		cancel:  cancel,
+		queues:  make(map[Notifier]*queue),
+	}
+}
+
+// Notifiers returns the notifiers queueing notifications to the given ones. Queues are kept for notifiers
+// passed again, hence notifiers must be comparable, and drained and stopped for notifiers not passed anymore.
+// Notifiers implementing Flusher are flushed once their queue is drained.
+//
+// Queued notifiers return as soon as the notification is queued, or with ErrQueueFull. The delivery gets as
+// long as was left of the context of the notification when it was queued.
+func (d *Dispatcher) Notifiers(notifiers ...Notifier) []Notifier {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	queues := make(map[Notifier]*queue, len(notifiers))
+	queued := make([]Notifier, 0, len(notifiers))
+	for _, n := range notifiers {
+		q, ok := d.queues[n]
+		if !ok {
+			q = d.start(n)
+		}
+		queues[n] = q
+		queued = append(queued, q)
+	}
+
+	for n, q := range d.queues {
+		if _, ok := queues[n]; !ok {
+			q.close()
+		}
+	}
+	d.queues = queues
+
+	return queued
+}
+
+// start starts delivering the notifications queued to n. The caller must hold the lock.
+func (d *Dispatcher) start(n Notifier) *queue {
+	q := &queue{notifier: n, jobs: make(chan job, d.size)}
+	if d.closed {
+		q.closed = true
+		return q
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		for j := range q.jobs {
+			if err := q.deliver(d.ctx, j); err != nil && d.onError != nil {
+				d.onError(err)
+			}
+		}
+		if f, ok := n.(Flusher); ok {
+			if err := f.Flush(d.ctx); err != nil && d.onError != nil {
+				d.onError(errors.Wrap(err, "unable to flush notifications"))
+			}
+		}
+	}()
+
+	return q
+}
+
+// Close stops queueing notifications and waits for the queued ones to be delivered and notifiers flushed.
+// If ctx is done first, deliveries in progress are cancelled, the rest dropped and ctx's error returned.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	d.closed = true
+	for _, q := range d.queues {
+		q.close()
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// job is a queued notification.
+type job struct {
+	event   *Event        // event to notify of, nil for refreshes
+	now     time.Time     // now is the time of the refresh
+	open    []Incident    // open are the incidents to refresh
+	bounded bool          // bounded reports whether the delivery times out
+	timeout time.Duration // timeout is what was left of the context of the notification when it was queued
+}
+
+// queue is a Notifier queueing notifications to its notifier.
+type queue struct {
+	notifier Notifier
+	jobs     chan job
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Notify queues the event. It implements Notifier.
+func (q *queue) Notify(ctx context.Context, event Event) error {
+	return q.enqueue(ctx, job{event: &event})
+}
+
+// Refresh queues the refresh of the incidents if the notifier implements Refresher. It implements Refresher.
+func (q *queue) Refresh(ctx context.Context, now time.Time, incidents []Incident) error {
+	if _, ok := q.notifier.(Refresher); !ok {
+		return nil
+	}
+	return q.enqueue(ctx, job{now: now, open: incidents})
+}
+
+func (q *queue) enqueue(ctx context.Context, j job) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		j.bounded, j.timeout = true, time.Until(deadline)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+	select {
+	case q.jobs <- j:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// deliver delivers the queued notification j.
+func (q *queue) deliver(ctx context.Context, j job) error {
+	if j.bounded {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+
+	if j.event == nil {
+		if err := q.notifier.(Refresher).Refresh(ctx, j.now, j.open); err != nil {
+			return errors.Wrap(err, "unable to refresh incidents")
+		}
+		return nil
+	}
+	if err := q.notifier.Notify(ctx, *j.event); err != nil {
+		return errors.Wrapf(err, "unable to notify %s of %s", j.event.Type, j.event.Incident.Key)
+	}
+	return nil
+}
+
+// close stops queueing, notifications already queued are still delivered.
+func (q *queue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+}
@@ -0,0 +1,121 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingNotifier blocks deliveries until their context is done.
+type blockingNotifier struct {
+	started chan Event
+}
+
+func (n *blockingNotifier) Notify(ctx context.Context, event Event) error {
+	select {
+	case n.started <- event:
+	default:
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestDispatcher(t *testing.T) {
+	var errs []error
+	d := NewDispatcher(1, func(err error) { errs = append(errs, err) })
+
+	blocking := &blockingNotifier{started: make(chan Event, 1)}
+	m := NewAlertManager(0, d.Notifiers(blocking)...)
+
+	incidents := NewIncidents(nil, nil, nil, []*ProviderError{{Provider: "SQS", Err: errors.New("timeout")}})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Update returns as soon as notifications are queued, without waiting for delivery.
+	events, err := m.Update(ctx, time.Now(), incidents)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	<-blocking.started
+	assert.Len(t, m.Incidents(), 1)
+
+	// Notifications exceeding the queue of a blocked notifier are dropped.
+	_, err = m.Update(ctx, time.Now(), nil)
+	assert.NoError(t, err)
+	_, err = m.Update(ctx, time.Now(), incidents)
+	assert.ErrorIs(t, err, ErrQueueFull)
+	assert.ErrorContains(t, err, "unable to notify firing of provider_down|SQS: notification queue is full")
+
+	// Queued notifications are delivered on close, timing out with the context they were queued with.
+	assert.NoError(t, d.Close(context.Background()))
+	assert.Len(t, errs, 2)
+	assert.ErrorIs(t, errs[0], context.DeadlineExceeded)
+	assert.ErrorContains(t, errs[0], "unable to notify firing of provider_down|SQS")
+	assert.ErrorContains(t, errs[1], "unable to notify resolved of provider_down|SQS")
+
+	_, err = m.Update(ctx, time.Now(), nil)
+	assert.ErrorIs(t, err, ErrQueueClosed)
+}
+
+func TestDispatcher_Refresh(t *testing.T) {
+	d := NewDispatcher(10, nil)
+	refreshing, recording := &refreshingNotifier{}, &recordingNotifier{}
+	m := NewAlertManager(0, d.Notifiers(refreshing, recording)...)
+
+	incidents := NewIncidents(nil, nil, nil, []*ProviderError{{Provider: "SQS", Err: errors.New("timeout")}})
+	for _, conditions := range [][]Incident{incidents, nil} {
+		_, err := m.Update(context.Background(), time.Now(), conditions)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, d.Close(context.Background()))
+
+	// Refreshes are delivered to notifiers implementing Refresher only.
+	assert.Len(t, refreshing.events, 2)
+	assert.Len(t, refreshing.refreshed, 2)
+	assert.Len(t, recording.events, 2)
+}
+
+// flushingNotifier counts how often it is flushed.
+type flushingNotifier struct {
+	recordingNotifier
+	flushed int
+}
+
+func (n *flushingNotifier) Flush(ctx context.Context) error {
+	n.flushed++
+	return nil
+}
+
+func TestDispatcher_Notifiers(t *testing.T) {
+	d := NewDispatcher(1, nil)
+	a, b := &flushingNotifier{}, &flushingNotifier{}
+
+	first := d.Notifiers(a, b)
+	second := d.Notifiers(b)
+	assert.Same(t, first[1], second[0])
+
+	// Queues of removed notifiers are stopped and their notifiers flushed.
+	assert.ErrorIs(t, first[0].Notify(context.Background(), Event{}), ErrQueueClosed)
+	assert.NoError(t, second[0].Notify(context.Background(), Event{}))
+
+	assert.NoError(t, d.Close(context.Background()))
+	assert.Len(t, b.events, 1)
+	assert.Equal(t, 1, a.flushed)
+	assert.Equal(t, 1, b.flushed)
+}
+
+func TestDispatcher_CloseTimeout(t *testing.T) {
+	d := NewDispatcher(1, nil)
+	blocking := &blockingNotifier{started: make(chan Event, 1)}
+
+	n := d.Notifiers(blocking)[0]
+	assert.NoError(t, n.Notify(context.Background(), Event{}))
+	<-blocking.started
+
+	// Deliveries still in progress when the deadline passes are cancelled.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.Close(ctx), context.DeadlineExceeded)
+}
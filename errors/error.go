@@ -47,3 +47,14 @@ func Equals(err1, err2 error) bool {
 func Is(err, target error) bool {
 	return errors.Is(err, target)
 }
+
+// As finds the first error in err's chain that matches target, and if so, sets target to that error value and returns true.
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
+
+// Join returns an error wrapping the given errors, nil errors are discarded.
+// It returns nil if every error is nil.
+func Join(errs ...error) error {
+	return errors.Join(errs...)
+}
@@ -0,0 +1,133 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor/errors"
+)
+
+// StatusError is returned by providers when the upstream API responds with an unexpected HTTP status code.
+type StatusError struct {
+	StatusCode int
+}
+
+// Error implements error.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// ProviderError describes a provider which failed to return prices.
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+// Error implements error.
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("provider %s: %s", e.Provider, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout reports whether the provider failed to respond before the deadline.
+func (e *ProviderError) Timeout() bool {
+	return errors.Is(e.Err, context.DeadlineExceeded)
+}
+
+// StatusCode returns the HTTP status code returned by the provider, or zero if the failure was not caused by one.
+func (e *ProviderError) StatusCode() int {
+	var se *StatusError
+	if errors.As(e.Err, &se) {
+		return se.StatusCode
+	}
+	return 0
+}
+
+// FetchResult is the outcome of fetching prices from a set of providers.
+type FetchResult struct {
+	Prices    []PriceData              // Prices are the prices returned by all providers, in the order of providers
+	Failures  []*ProviderError         // Failures are the providers which failed to return prices
+	Missing   map[string]Pairs         // Missing are the requested pairs a provider responded without, keyed by provider name
+	Durations map[string]time.Duration // Durations are the response times, keyed by provider name
+}
+
+// Failed reports whether the named provider failed to return prices.
+func (r FetchResult) Failed(provider string) bool {
+	for _, f := range r.Failures {
+		if f.Provider == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetch fetches prices for the given pairs from the given providers.
+// Providers are queried concurrently and share the same deadline, results are reported in the order of providers.
+func Fetch(ctx context.Context, providers []Provider, pairs Pairs, timeout time.Duration) FetchResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type response struct {
+		prices     []PriceData
+		err        error
+		duration   time.Duration
+		observedAt time.Time // observedAt is the time the provider responded
+	}
+	responses := make([]response, len(providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			p, err := provider.GetPrices(ctx, pairs)
+			observedAt := time.Now()
+			responses[i] = response{prices: p, err: err, duration: observedAt.Sub(start), observedAt: observedAt}
+		}()
+	}
+	wg.Wait()
+
+	result := FetchResult{
+		Missing:   make(map[string]Pairs),
+		Durations: make(map[string]time.Duration, len(providers)),
+	}
+	for i, provider := range providers {
+		name, resp := provider.Name(), responses[i]
+
+		result.Durations[name] = resp.duration
+		ProviderLatencyHistogram.WithLabelValues(name).Observe(resp.duration.Seconds())
+
+		if resp.err != nil {
+			result.Failures = append(result.Failures, &ProviderError{Provider: name, Err: resp.err})
+			ProviderErrorCounter.WithLabelValues(name).Inc()
+			ProviderUpGauge.WithLabelValues(name).Set(0)
+			continue
+		}
+		ProviderUpGauge.WithLabelValues(name).Set(1)
+
+		returned := make(map[Pair]bool, len(resp.prices))
+		for _, p := range resp.prices {
+			if p.ObservedAt.IsZero() {
+				p.ObservedAt = resp.observedAt
+			}
+			returned[p.Pair] = true
+			result.Prices = append(result.Prices, p)
+		}
+
+		for _, pair := range pairs {
+			if !returned[pair] {
+				result.Missing[name] = append(result.Missing[name], pair)
+			}
+		}
+	}
+
+	return result
+}
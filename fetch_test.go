@@ -0,0 +1,102 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubProvider is a Provider returning a fixed price for every pair after a delay.
+type stubProvider struct {
+	name  string
+	price float64
+	delay time.Duration
+	err   error
+}
+
+func (p *stubProvider) Name() string { return p.name }
+
+func (p *stubProvider) GetPrices(ctx context.Context, pairs Pairs) ([]PriceData, error) {
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	var prices []PriceData
+	for _, pair := range pairs {
+		prices = append(prices, PriceData{Pair: pair, Service: p.name, Price: p.price})
+	}
+	return prices, nil
+}
+
+func TestFetch(t *testing.T) {
+	pairs := Pairs{{Base: OSMO, Quote: USD}}
+	providers := []Provider{
+		&stubProvider{name: "Slow", price: 1, delay: 100 * time.Millisecond},
+		&stubProvider{name: "Fast", price: 2, delay: 50 * time.Millisecond},
+		&stubProvider{name: "Broken", err: errors.New("unavailable")},
+		&stubProvider{name: "Stuck", price: 3, delay: time.Hour},
+	}
+
+	start := time.Now()
+	result := Fetch(context.Background(), providers, pairs, 300*time.Millisecond)
+	elapsed := time.Since(start)
+
+	// Providers are queried concurrently, the stuck provider is cut off by the shared deadline.
+	assert.Less(t, elapsed, 450*time.Millisecond)
+
+	// Prices are merged in the order of providers, not in the order of completion.
+	assert.Len(t, result.Prices, 2)
+	assert.Equal(t, "Slow", result.Prices[0].Service)
+	assert.Equal(t, "Fast", result.Prices[1].Service)
+	for _, p := range result.Prices {
+		assert.False(t, p.ObservedAt.IsZero())
+	}
+	// Prices are stamped with the time their own provider responded, not the time the slowest one did.
+	assert.True(t, result.Prices[1].ObservedAt.Before(result.Prices[0].ObservedAt))
+	assert.Less(t, result.Prices[1].ObservedAt.Sub(start), 100*time.Millisecond)
+
+	// Failures are reported in the order of providers with their cause.
+	assert.Len(t, result.Failures, 2)
+	assert.Equal(t, "Broken", result.Failures[0].Provider)
+	assert.EqualError(t, result.Failures[0].Unwrap(), "unavailable")
+	assert.False(t, result.Failures[0].Timeout())
+	assert.Equal(t, "Stuck", result.Failures[1].Provider)
+	assert.True(t, result.Failures[1].Timeout())
+	assert.True(t, result.Failed("Stuck"))
+	assert.False(t, result.Failed("Fast"))
+
+	assert.Len(t, result.Durations, 4)
+	assert.GreaterOrEqual(t, result.Durations["Slow"], 100*time.Millisecond)
+}
+
+func TestFetch_MissingPairs(t *testing.T) {
+	atom := Pair{Base: "atom", Quote: USD}
+	osmo := Pair{Base: OSMO, Quote: USD}
+	providers := []Provider{&partialProvider{name: "Partial", pair: osmo}}
+
+	result := Fetch(context.Background(), providers, Pairs{osmo, atom}, time.Second)
+	assert.Len(t, result.Prices, 1)
+	assert.Empty(t, result.Failures)
+	assert.Equal(t, map[string]Pairs{"Partial": {atom}}, result.Missing)
+}
+
+// partialProvider is a Provider returning a price for a single pair only.
+type partialProvider struct {
+	name string
+	pair Pair
+}
+
+func (p *partialProvider) Name() string { return p.name }
+
+func (p *partialProvider) GetPrices(ctx context.Context, pairs Pairs) ([]PriceData, error) {
+	return []PriceData{{Pair: p.pair, Service: p.name, Price: 1}}, nil
+}
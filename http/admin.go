@@ -0,0 +1,53 @@
+package http
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Reloader reloads the service configuration.
+type Reloader interface {
+	Reload() error
+}
+
+// authorize returns a handler serving requests authorized by the bearer token with h, and rejecting
+// the others with 401 Unauthorized.
+func authorize(token string, h http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			respond(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+}
+
+// reloadHandler returns a handler triggering a configuration reload.
+// A rejected configuration is reported with 422 Unprocessable Entity, the active configuration is kept.
+// The reason is logged by the reloader only, as it may reveal file paths and configuration values.
+func reloadHandler(reloader Reloader) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := reloader.Reload(); err != nil {
+			respond(w, http.StatusUnprocessableEntity, map[string]string{"error": "configuration rejected, see the service log"})
+			return
+		}
+		respond(w, http.StatusOK, map[string]string{"status": "reloaded"})
+	}
+}
+
+// respond writes v as JSON response with the given status code.
+// v is encoded before the status is written, so that an encoding failure is reported as 500 Internal Server Error
+// instead of an empty response with the given status.
+func respond(w http.ResponseWriter, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		status, body = http.StatusInternalServerError, []byte(`{"error":"unable to encode response"}`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/deividaspetraitis/price-monitor/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// reloaderFunc is a Reloader calling itself.
+type reloaderFunc func() error
+
+func (f reloaderFunc) Reload() error {
+	return f()
+}
+
+func TestReload(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		authorization  string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "reloaded",
+			token:          "secret",
+			authorization:  "Bearer secret",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"reloaded"}`,
+		},
+		{
+			name:           "rejected configuration",
+			token:          "secret",
+			authorization:  "Bearer secret",
+			err:            errors.New("invalid configuration: unable to parse /etc/monitord/monitord.yaml"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `{"error":"configuration rejected, see the service log"}`,
+		},
+		{
+			name:           "wrong token",
+			token:          "secret",
+			authorization:  "Bearer guess",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"unauthorized"}`,
+		},
+		{
+			name:           "missing token",
+			token:          "secret",
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"unauthorized"}`,
+		},
+		{
+			name:           "disabled without token",
+			authorization:  "Bearer ",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reloads := 0
+			reloader := reloaderFunc(func() error {
+				reloads++
+				return tt.err
+			})
+			api := API(make(chan os.Signal, 1), reloader, breachList{}, tt.token)
+
+			r := httptest.NewRequest(http.MethodPost, "/admin/reload", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			api.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+			if tt.expectedStatus == http.StatusOK || tt.expectedStatus == http.StatusUnprocessableEntity {
+				assert.Equal(t, 1, reloads)
+			} else {
+				assert.Zero(t, reloads)
+			}
+		})
+	}
+}
@@ -38,7 +38,8 @@ func (a *App) SignalShutdown() {
 }
 
 // API constructs an http.Handler with all application routes defined.
-func API(shutdown chan os.Signal) http.Handler {
+// Admin routes require adminToken as bearer token, they are not served if it is empty.
+func API(shutdown chan os.Signal, reloader Reloader, breaches BreachLister, adminToken string) http.Handler {
 	// =========================================================================
 	// Construct the web app api which holds all routes as well as common Middleware.
 
@@ -48,6 +49,10 @@ func API(shutdown chan os.Signal) http.Handler {
 	// Construct and attach relevant handlers to web app api
 
 	api.API.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	if adminToken != "" {
+		api.API.Handle("/admin/reload", authorize(adminToken, reloadHandler(reloader))).Methods(http.MethodPost)
+	}
+	api.API.Handle("/breaches", breachesHandler(breaches)).Methods(http.MethodGet)
 
 	router := mux.NewRouter()
 
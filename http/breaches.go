@@ -0,0 +1,60 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+)
+
+// BreachLister lists the current price difference breaches.
+type BreachLister interface {
+	Breaches() []monitor.Breach
+}
+
+// breach is the JSON representation of a monitor.Breach.
+type breach struct {
+	Pair        string         `json:"pair"`
+	ProviderA   string         `json:"provider_a"`
+	ProviderB   string         `json:"provider_b"`
+	PriceA      monitor.Number `json:"price_a"`
+	PriceB      monitor.Number `json:"price_b"`
+	Difference  monitor.Number `json:"difference"`
+	Relative    monitor.Number `json:"relative"` // Relative is infinite if the reference price is zero
+	Direction   string         `json:"direction"`
+	Active      bool           `json:"active"`
+	Since       time.Time      `json:"since"`
+	LastSeen    time.Time      `json:"last_seen"`
+	Duration    string         `json:"duration"`
+	Consecutive int            `json:"consecutive"`
+	Healthy     int            `json:"healthy"`
+	Missed      int            `json:"missed"` // Missed is the number of consecutive cycles the pair was not compared
+}
+
+// breachesHandler returns a handler listing current breaches, pending and active.
+func breachesHandler(lister BreachLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		breaches := []breach{}
+		for _, b := range lister.Breaches() {
+			d := b.Difference
+			breaches = append(breaches, breach{
+				Pair:        d.Pair.String(),
+				ProviderA:   d.ServiceA,
+				ProviderB:   d.ServiceB,
+				PriceA:      monitor.Number(d.PriceA),
+				PriceB:      monitor.Number(d.PriceB),
+				Difference:  monitor.Number(d.Difference),
+				Relative:    monitor.Number(d.Relative),
+				Direction:   string(d.Direction),
+				Active:      b.Active,
+				Since:       b.Since,
+				LastSeen:    b.LastSeen,
+				Duration:    b.Duration().String(),
+				Consecutive: b.Consecutive,
+				Healthy:     b.Healthy,
+				Missed:      b.Missed,
+			})
+		}
+		respond(w, http.StatusOK, breaches)
+	}
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+// breachList is a BreachLister of fixed breaches.
+type breachList []monitor.Breach
+
+func (l breachList) Breaches() []monitor.Breach {
+	return l
+}
+
+func TestBreachesHandler(t *testing.T) {
+	since := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	breaches := breachList{{
+		Key:         "osmo/usd|SQS|CoinGecko",
+		Since:       since,
+		LastSeen:    since.Add(time.Minute),
+		Consecutive: 2,
+		Active:      true,
+		Difference: monitor.PriceDifference{
+			Pair:       monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD},
+			ServiceA:   "SQS",
+			ServiceB:   "CoinGecko",
+			PriceA:     0.5,
+			Difference: 0.5,
+			Relative:   math.Inf(1),
+			Direction:  monitor.Over,
+		},
+	}}
+
+	w := httptest.NewRecorder()
+	breachesHandler(breaches)(w, httptest.NewRequest(http.MethodGet, "/breaches", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `[{
+		"pair": "osmo/usd",
+		"provider_a": "SQS",
+		"provider_b": "CoinGecko",
+		"price_a": 0.5,
+		"price_b": 0,
+		"difference": 0.5,
+		"relative": "+Inf",
+		"direction": "over",
+		"active": true,
+		"since": "2024-12-01T10:00:00Z",
+		"last_seen": "2024-12-01T10:01:00Z",
+		"duration": "1m0s",
+		"consecutive": 2,
+		"healthy": 0,
+		"missed": 0
+	}]`, w.Body.String())
+}
+
+func TestRespond_EncodingError(t *testing.T) {
+	w := httptest.NewRecorder()
+	respond(w, http.StatusOK, map[string]any{"value": math.NaN()})
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"unable to encode response"}`, w.Body.String())
+}
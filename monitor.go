@@ -2,84 +2,213 @@ package monitor
 
 import (
 	"context"
+	"encoding/json"
 	"math"
+	"sort"
+	"strconv"
 	"time"
-
-	"github.com/deividaspetraitis/price-monitor/log"
 )
 
 type PriceData struct {
-	Pair    Pair
-	Service string
-	Price   float64
+	Pair       Pair
+	Service    string
+	Price      float64
+	ObservedAt time.Time // ObservedAt is the time the price was fetched from the provider
+	UpdatedAt  time.Time // UpdatedAt is the time the upstream quote was last updated, zero if the provider does not expose it
+	Confidence float64   // Confidence is the half width of the confidence interval around Price, zero if the provider does not expose it
+}
+
+// Age returns how old the upstream quote was when it was observed, or zero if the update time is unknown.
+func (p PriceData) Age() time.Duration {
+	if p.UpdatedAt.IsZero() {
+		return 0
+	}
+	observedAt := p.ObservedAt
+	if observedAt.IsZero() {
+		observedAt = time.Now()
+	}
+	return observedAt.Sub(p.UpdatedAt)
 }
 
 type Provider interface {
+	// Name returns the name of the provider, the same as PriceData.Service of the prices it returns.
+	Name() string
+
 	GetPrices(ctx context.Context, cryptos Pairs) ([]PriceData, error)
 }
 
-// Fetch fetches prices for the given pairs from the given providers.
-func Fetch(ctx context.Context, providers []Provider, pairs []Pair, timeout time.Duration, logger log.Logger) []PriceData {
-	var prices []PriceData
-	for _, provider := range providers {
-		providerCtx, cancel := context.WithTimeout(ctx, timeout)
-		defer cancel()
+// PairResolver is implemented by providers which can tell ahead of fetching whether they are able to price a pair.
+// Pairs a provider can't resolve are skipped by GetPrices and reported missing.
+type PairResolver interface {
+	// Resolve returns why the provider can't price the pair, nil if it can.
+	Resolve(pair Pair) error
+}
 
-		p, err := provider.GetPrices(providerCtx, pairs)
-		if err != nil {
-			logger.Printf("Error fetching prices: %s", err)
-			continue
-		}
+// PriceDifference holds details about a detected price mismatch.
+type PriceDifference struct {
+	Pair        Pair
+	ServiceA    string // ServiceA is the provider which reported PriceA
+	ServiceB    string // ServiceB is the provider which reported PriceB
+	PriceA      float64
+	PriceB      float64
+	ObservedAtA time.Time // ObservedAtA is the time PriceA was fetched
+	ObservedAtB time.Time // ObservedAtB is the time PriceB was fetched
+	Difference  float64   // Difference is the absolute difference between PriceA and PriceB
+	Relative    float64   // Relative is Difference as a fraction of the reference price, the mean of PriceA and PriceB or the consensus price
+	Direction   Direction // Direction tells whether PriceA is over or under PriceB
+	Rule        Rule      // Rule is the rule whose threshold was exceeded
+}
 
-		for crypto, data := range p {
-			logger.Printf("%v: %v", crypto, data)
-		}
+// Exceedance returns the difference as a multiple of the rule threshold.
+func (d PriceDifference) Exceedance() float64 {
+	return d.Rule.Exceedance(d.Difference, d.Relative)
+}
 
-		prices = append(prices, p...)
+// Direction tells whether a price is over or under the price it is compared with.
+type Direction string
+
+// List of price directions.
+const (
+	Over  Direction = "over"
+	Under Direction = "under"
+)
+
+// direction returns the Direction of a relative to b.
+func direction(a, b float64) Direction {
+	switch {
+	case a > b:
+		return Over
+	case a < b:
+		return Under
 	}
+	return ""
+}
 
-	return prices
+// StalePrice holds details about a price whose upstream quote is older than its rule allows.
+type StalePrice struct {
+	PriceData
+	Age  time.Duration // Age is how old the quote was when it was observed
+	Rule Rule          // Rule is the rule whose maximum age was exceeded
 }
 
-// PriceDifference holds details about a detected price mismatch.
-type PriceDifference struct {
-	Pair       Pair
-	PriceA     float64
-	PriceB     float64
-	Difference float64
-	Threshold  float64
+// Findings are the results of comparing prices.
+type Findings struct {
+	Differences []PriceDifference // Differences are price mismatches above the rule threshold
+	Within      []PriceDifference // Within are the comparisons which stayed within the rule threshold
+	Stale       []StalePrice      // Stale are prices older than the rule maximum age, they are not compared
+	NoConsensus []NoConsensus     // NoConsensus are pairs for which fewer providers than the rule quorum answered
 }
 
-// Compare checks price differences for each Pair and returns mismatches above the threshold.
-func Compare(prices []PriceData, threshold float64) []PriceDifference {
-	pairPrices := make(map[Pair][]float64)
-	var diffs []PriceDifference
+// Compare checks price differences for each Pair according to its rule and returns mismatches above the rule threshold.
+// Prices older than the rule maximum age are reported as stale and left out of the comparison.
+// Pairs with a disabled rule or fewer prices than the rule requires are skipped.
+func Compare(prices []PriceData, rules Rules) Findings {
+	pairPrices := make(map[Pair][]PriceData)
+	var pairs Pairs
+	var findings Findings
 
-	// Group prices by Pair
+	// Group prices by Pair, keeping the order in which pairs were first seen
 	for _, data := range prices {
-		pairPrices[data.Pair] = append(pairPrices[data.Pair], data.Price)
+		rule := rules.For(data.Pair)
+		if !rule.Enabled {
+			continue
+		}
+
+		if age := data.Age(); rule.MaxAge > 0 && age > rule.MaxAge {
+			findings.Stale = append(findings.Stale, StalePrice{PriceData: data, Age: age, Rule: rule})
+			StalePriceCounter.WithLabelValues(data.Pair.String(), data.Service).Inc()
+			continue
+		}
+
+		if _, ok := pairPrices[data.Pair]; !ok {
+			pairs = append(pairs, data.Pair)
+		}
+		pairPrices[data.Pair] = append(pairPrices[data.Pair], data)
 	}
 
+	// Pairs with an explicit rule requiring a quorum are checked even if no provider answered for them
+	var unanswered Pairs
+	for pair, rule := range rules.Pairs {
+		if _, ok := pairPrices[pair]; !ok && rule.Enabled && rule.Quorum > 0 {
+			unanswered = append(unanswered, pair)
+		}
+	}
+	sort.Slice(unanswered, func(i, j int) bool { return unanswered[i].String() < unanswered[j].String() })
+	pairs = append(pairs, unanswered...)
+
 	// Compare prices for each Pair
-	for pair, ps := range pairPrices {
-		for i := 0; i < len(ps); i++ {
-			for j := i + 1; j < len(ps); j++ {
-				diff := math.Abs(ps[i] - ps[j])
-				if diff > threshold {
-					diffs = append(diffs, PriceDifference{
-						Pair:       pair,
-						PriceA:     ps[i],
-						PriceB:     ps[j],
-						Difference: diff,
-						Threshold:  threshold,
-					})
-					PricingErrorCounter.Inc() // Increment error counter
-				}
-			}
+	for _, pair := range pairs {
+		ps := pairPrices[pair]
+		rule := rules.For(pair)
+
+		if rule.Strategy.consensus() && len(ps) < rule.Quorum {
+			findings.NoConsensus = append(findings.NoConsensus, newNoConsensus(pair, ps, rule))
+			NoConsensusCounter.WithLabelValues(pair.String()).Inc()
+			continue
+		}
+
+		if len(ps) < rule.minProviders() {
+			continue
 		}
+
+		var diffs, within []PriceDifference
+		switch {
+		case rule.Strategy.consensus():
+			diffs, within = compareConsensus(pair, ps, rule)
+		case rule.Strategy == Reference:
+			diffs, within = compareReference(pair, ps, rule)
+		default:
+			diffs, within = comparePairwise(pair, ps, rule)
+		}
+
+		findings.Differences = append(findings.Differences, diffs...)
+		findings.Within = append(findings.Within, within...)
 	}
 
 	PricingHeartbeatCounter.Inc() // Send heartbeat signal
 
-	return diffs
+	return findings
+}
+
+// comparePairwise compares every price of the pair with every other and returns mismatches above the rule threshold
+// and the comparisons within it.
+func comparePairwise(pair Pair, ps []PriceData, rule Rule) (diffs, within []PriceDifference) {
+	for i := 0; i < len(ps); i++ {
+		for j := i + 1; j < len(ps); j++ {
+			a, b := ps[i], ps[j]
+			diff, rel := deviation(a.Price, b.Price, (a.Price+b.Price)/2)
+			d := PriceDifference{
+				Pair:        pair,
+				ServiceA:    a.Service,
+				ServiceB:    b.Service,
+				PriceA:      a.Price,
+				PriceB:      b.Price,
+				ObservedAtA: a.ObservedAt,
+				ObservedAtB: b.ObservedAt,
+				Difference:  diff,
+				Relative:    rel,
+				Direction:   direction(a.Price, b.Price),
+				Rule:        rule,
+			}
+			if rule.exceededBeyond(diff, rel, a.Confidence+b.Confidence) {
+				diffs = append(diffs, d)
+			} else {
+				within = append(within, d)
+			}
+		}
+	}
+	return diffs, within
+}
+
+// Number is a float64 encoded as a JSON number, or as a string if it is infinite or NaN,
+// which encoding/json refuses to encode, e.g. the relative difference to a zero price.
+type Number float64
+
+// MarshalJSON implements json.Marshaler.
+func (n Number) MarshalJSON() ([]byte, error) {
+	f := float64(n)
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return json.Marshal(strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	return json.Marshal(f)
 }
@@ -2,21 +2,24 @@ package monitor
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCompare(t *testing.T) {
 	const (
-		BTC = iota + 3
-		ETH
+		BTC Coin = "btc"
+		ETH Coin = "eth"
 	)
 
+	observedAt := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+
 	tests := []struct {
-		name      string
-		prices    []PriceData
-		threshold float64
-		expected  []PriceDifference
+		name     string
+		prices   []PriceData
+		rules    Rules
+		expected Findings
 	}{
 		{
 			name: "no differences",
@@ -26,8 +29,8 @@ func TestCompare(t *testing.T) {
 				{Pair: Pair{Base: ETH, Quote: USD}, Service: "Provider1", Price: 30.00},
 				{Pair: Pair{Base: ETH, Quote: USD}, Service: "Provider2", Price: 30.04},
 			},
-			threshold: 0.05,
-			expected:  nil,
+			rules:    Rules{Default: Rule{Threshold: 0.05, Enabled: true}},
+			expected: Findings{},
 		},
 		{
 			name: "one difference above threshold",
@@ -37,23 +40,173 @@ func TestCompare(t *testing.T) {
 				{Pair: Pair{Base: OSMO, Quote: USD}, Service: "Provider1", Price: 0.198925},
 				{Pair: Pair{Base: OSMO, Quote: USD}, Service: "Provider2", Price: 0.1697239635201354},
 			},
-			threshold: 0.01,
-			expected: []PriceDifference{
+			rules: Rules{Default: Rule{Threshold: 0.01, Mode: Absolute, Enabled: true}},
+			expected: Findings{Differences: []PriceDifference{
 				{
 					Pair:       Pair{Base: OSMO, Quote: USD},
+					ServiceA:   "Provider1",
+					ServiceB:   "Provider2",
 					PriceA:     0.198925,
 					PriceB:     0.1697239635201354,
 					Difference: 0.02920103647986458,
-					Threshold:  0.01,
+					Relative:   0.15842191010673834,
+					Direction:  Over,
+					Rule:       Rule{Threshold: 0.01, Mode: Absolute, Enabled: true},
+				},
+			}},
+		},
+		{
+			name: "relative threshold",
+			prices: []PriceData{
+				{Pair: Pair{Base: BTC, Quote: USD}, Service: "Provider1", Price: 50000},
+				{Pair: Pair{Base: BTC, Quote: USD}, Service: "Provider2", Price: 50100},
+				{Pair: Pair{Base: OSMO, Quote: USD}, Service: "Provider1", Price: 0.20, ObservedAt: observedAt},
+				{Pair: Pair{Base: OSMO, Quote: USD}, Service: "Provider2", Price: 0.18, ObservedAt: observedAt.Add(time.Second)},
+			},
+			rules: Rules{Default: Rule{Threshold: 1, Mode: Percent, Enabled: true}},
+			expected: Findings{Differences: []PriceDifference{
+				{
+					Pair:        Pair{Base: OSMO, Quote: USD},
+					ServiceA:    "Provider1",
+					ServiceB:    "Provider2",
+					ObservedAtA: observedAt,
+					ObservedAtB: observedAt.Add(time.Second),
+					PriceA:      0.20,
+					PriceB:      0.18,
+					Difference:  0.020000000000000018,
+					Relative:    0.10526315789473693,
+					Direction:   Over,
+					Rule:        Rule{Threshold: 1, Mode: Percent, Enabled: true},
+				},
+			}},
+		},
+		{
+			name: "per pair threshold",
+			prices: []PriceData{
+				{Pair: Pair{Base: BTC, Quote: USD}, Service: "Provider1", Price: 50000},
+				{Pair: Pair{Base: BTC, Quote: USD}, Service: "Provider2", Price: 50100},
+				{Pair: Pair{Base: OSMO, Quote: USD}, Service: "Provider1", Price: 0.20},
+				{Pair: Pair{Base: OSMO, Quote: USD}, Service: "Provider2", Price: 0.18},
+			},
+			rules: Rules{
+				Default: Rule{Threshold: 2000, Mode: BasisPoints, Enabled: true},
+				Pairs: map[Pair]Rule{
+					{Base: BTC, Quote: USD}: {Threshold: 10, Mode: BasisPoints, Enabled: true},
+				},
+			},
+			expected: Findings{Differences: []PriceDifference{
+				{
+					Pair:       Pair{Base: BTC, Quote: USD},
+					ServiceA:   "Provider1",
+					ServiceB:   "Provider2",
+					PriceA:     50000,
+					PriceB:     50100,
+					Difference: 100,
+					Relative:   0.001998001998001998,
+					Direction:  Under,
+					Rule:       Rule{Threshold: 10, Mode: BasisPoints, Enabled: true},
+				},
+			}},
+		},
+		{
+			name: "disabled pair and too few providers",
+			prices: []PriceData{
+				{Pair: Pair{Base: BTC, Quote: USD}, Service: "Provider1", Price: 50000},
+				{Pair: Pair{Base: BTC, Quote: USD}, Service: "Provider2", Price: 60000},
+				{Pair: Pair{Base: OSMO, Quote: USD}, Service: "Provider1", Price: 0.20},
+				{Pair: Pair{Base: OSMO, Quote: USD}, Service: "Provider2", Price: 0.18},
+			},
+			rules: Rules{
+				Default: Rule{Threshold: 0.01, Enabled: true, MinProviders: 3},
+				Pairs: map[Pair]Rule{
+					{Base: BTC, Quote: USD}: {Threshold: 0.01, Enabled: false},
 				},
 			},
+			expected: Findings{},
+		},
+		{
+			name: "stale price is reported and not compared",
+			prices: []PriceData{
+				{Pair: Pair{Base: OSMO, Quote: USD}, Service: "Provider1", Price: 0.20, ObservedAt: observedAt, UpdatedAt: observedAt.Add(-time.Hour)},
+				{Pair: Pair{Base: OSMO, Quote: USD}, Service: "Provider2", Price: 0.18, ObservedAt: observedAt, UpdatedAt: observedAt.Add(-time.Minute)},
+				{Pair: Pair{Base: OSMO, Quote: USD}, Service: "Provider3", Price: 0.18, ObservedAt: observedAt},
+			},
+			rules: Rules{Default: Rule{Threshold: 0.01, MaxAge: 10 * time.Minute, Enabled: true}},
+			expected: Findings{Stale: []StalePrice{
+				{
+					PriceData: PriceData{Pair: Pair{Base: OSMO, Quote: USD}, Service: "Provider1", Price: 0.20, ObservedAt: observedAt, UpdatedAt: observedAt.Add(-time.Hour)},
+					Age:       time.Hour,
+					Rule:      Rule{Threshold: 0.01, MaxAge: 10 * time.Minute, Enabled: true},
+				},
+			}},
+		},
+		{
+			name: "deviation within confidence intervals is tolerated",
+			prices: []PriceData{
+				{Pair: Pair{Base: BTC, Quote: USD}, Service: "Provider1", Price: 100, Confidence: 1.5},
+				{Pair: Pair{Base: BTC, Quote: USD}, Service: "Provider2", Price: 102, Confidence: 1},
+				{Pair: Pair{Base: ETH, Quote: USD}, Service: "Provider1", Price: 100, Confidence: 0.5},
+				{Pair: Pair{Base: ETH, Quote: USD}, Service: "Provider2", Price: 103, Confidence: 0.5},
+			},
+			rules: Rules{Default: Rule{Threshold: 1.5, ConfidenceTolerance: true, Enabled: true}},
+			expected: Findings{Differences: []PriceDifference{
+				{
+					Pair:       Pair{Base: ETH, Quote: USD},
+					ServiceA:   "Provider1",
+					ServiceB:   "Provider2",
+					PriceA:     100,
+					PriceB:     103,
+					Difference: 3,
+					Relative:   3 / 101.5,
+					Direction:  Under,
+					Rule:       Rule{Threshold: 1.5, ConfidenceTolerance: true, Enabled: true},
+				},
+			}},
+		},
+		{
+			name: "confidence intervals are ignored unless tolerated",
+			prices: []PriceData{
+				{Pair: Pair{Base: BTC, Quote: USD}, Service: "Provider1", Price: 100, Confidence: 1.5},
+				{Pair: Pair{Base: BTC, Quote: USD}, Service: "Provider2", Price: 102, Confidence: 1},
+			},
+			rules: Rules{Default: Rule{Threshold: 1.5, Enabled: true}},
+			expected: Findings{Differences: []PriceDifference{
+				{
+					Pair:       Pair{Base: BTC, Quote: USD},
+					ServiceA:   "Provider1",
+					ServiceB:   "Provider2",
+					PriceA:     100,
+					PriceB:     102,
+					Difference: 2,
+					Relative:   2.0 / 101,
+					Direction:  Under,
+					Rule:       Rule{Threshold: 1.5, Enabled: true},
+				},
+			}},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := Compare(tt.prices, tt.threshold)
+			result := Compare(tt.prices, tt.rules)
+			result.Within = nil // covered by TestCompare_Within
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
+
+func TestCompare_Within(t *testing.T) {
+	pair := Pair{Base: OSMO, Quote: USD}
+	prices := []PriceData{
+		{Pair: pair, Service: "Provider1", Price: 10},
+		{Pair: pair, Service: "Provider2", Price: 10.01},
+		{Pair: pair, Service: "Provider3", Price: 12},
+	}
+
+	result := Compare(prices, Rules{Default: Rule{Threshold: 0.05, Mode: Absolute, Enabled: true}})
+	assert.Len(t, result.Differences, 2)
+	if assert.Len(t, result.Within, 1) {
+		assert.Equal(t, "Provider1", result.Within[0].ServiceA)
+		assert.Equal(t, "Provider2", result.Within[0].ServiceB)
+	}
+}
@@ -0,0 +1,130 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+)
+
+// Alertmanager is a monitor.Notifier pushing incidents as alerts to the Prometheus Alertmanager API v2.
+// Open incidents are pushed every cycle with endsAt extended by TTL, so that alerts of a stopped monitor
+// resolve on their own, and resolved incidents are pushed with endsAt set to the time they resolved.
+type Alertmanager struct {
+	URL          string        // URL is the base URL of Alertmanager, e.g. http://alertmanager:9093
+	TTL          time.Duration // TTL is how long a pushed alert stays firing unless refreshed, it must exceed the monitoring interval
+	GeneratorURL string        // GeneratorURL, if set, is linked from alerts
+	Templates    *Templates    // Templates, if set, override the built-in summary annotation
+	HTTPClient   *http.Client
+
+	mu         sync.Mutex
+	severities map[string]string // severities holds the severity of open incidents, fixed when they fire to keep label sets stable
+}
+
+// NewAlertmanager creates a new Alertmanager notifier pushing to the Alertmanager at url.
+func NewAlertmanager(url string, ttl time.Duration) *Alertmanager {
+	return &Alertmanager{
+		URL:        strings.TrimSuffix(url, "/"),
+		TTL:        ttl,
+		HTTPClient: &http.Client{},
+		severities: make(map[string]string),
+	}
+}
+
+// alertmanagerAlert is an alert of the Alertmanager API v2.
+type alertmanagerAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// alertNames holds the alertname label of each incident kind.
+var alertNames = map[monitor.IncidentKind]string{
+	monitor.DeviationIncident:    "PriceDeviation",
+	monitor.StaleIncident:        "StalePrice",
+	monitor.NoConsensusIncident:  "PriceNoConsensus",
+	monitor.ProviderDownIncident: "PriceProviderDown",
+}
+
+// Notify implements monitor.Notifier. Only resolutions are pushed, open incidents are pushed by Refresh.
+func (a *Alertmanager) Notify(ctx context.Context, event monitor.Event) error {
+	if event.Type != monitor.Resolved {
+		return nil
+	}
+
+	a.mu.Lock()
+	alert := a.alert(event, event.Incident.ResolvedAt)
+	delete(a.severities, event.Incident.Key)
+	a.mu.Unlock()
+
+	return a.push(ctx, []alertmanagerAlert{alert})
+}
+
+// Refresh implements monitor.Refresher.
+func (a *Alertmanager) Refresh(ctx context.Context, now time.Time, incidents []monitor.Incident) error {
+	if len(incidents) == 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	alerts := make([]alertmanagerAlert, 0, len(incidents))
+	for _, i := range incidents {
+		alerts = append(alerts, a.alert(monitor.Event{Type: monitor.Firing, Incident: i, Time: now}, now.Add(a.TTL)))
+	}
+	a.mu.Unlock()
+
+	return a.push(ctx, alerts)
+}
+
+// push posts alerts to Alertmanager.
+func (a *Alertmanager) push(ctx context.Context, alerts []alertmanagerAlert) error {
+	if err := postJSON(ctx, a.HTTPClient, a.URL+"/api/v2/alerts", alerts); err != nil {
+		return fmt.Errorf("alertmanager: %w", err)
+	}
+	return nil
+}
+
+// alert returns the Alertmanager alert of the incident of event ending at endsAt. The caller must hold the lock.
+func (a *Alertmanager) alert(event monitor.Event, endsAt time.Time) alertmanagerAlert {
+	i := event.Incident
+	sev, ok := a.severities[i.Key]
+	if !ok {
+		sev = severity(i)
+		a.severities[i.Key] = sev
+	}
+
+	labels := map[string]string{
+		"alertname": alertNames[i.Kind],
+		"kind":      string(i.Kind),
+		"severity":  sev,
+	}
+	if i.Pair != (monitor.Pair{}) {
+		labels["pair"] = i.Pair.String()
+		labels["base"] = string(i.Pair.Base)
+		labels["quote"] = string(i.Pair.Quote)
+	}
+	switch {
+	case i.Difference != nil:
+		labels["provider_a"] = i.Difference.ServiceA
+		labels["provider_b"] = i.Difference.ServiceB
+	case i.Kind != monitor.NoConsensusIncident && len(i.Providers) > 0:
+		labels["provider_a"] = i.Providers[0]
+	}
+
+	return alertmanagerAlert{
+		Labels: labels,
+		Annotations: map[string]string{
+			"summary":     a.Templates.render(event, i.Summary),
+			"incident_id": i.ID,
+		},
+		StartsAt:     i.StartedAt,
+		EndsAt:       endsAt,
+		GeneratorURL: a.GeneratorURL,
+	}
+}
@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertmanager(t *testing.T) {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	pair := monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}
+	rule := monitor.Rule{Threshold: 0.01, Mode: monitor.Absolute}
+
+	var pushes [][]alertmanagerAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v2/alerts", r.URL.Path)
+
+		var alerts []alertmanagerAlert
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&alerts))
+		pushes = append(pushes, alerts)
+	}))
+	defer server.Close()
+
+	m := monitor.NewAlertManager(0, NewAlertmanager(server.URL+"/", 3*time.Minute))
+	cycle := func(minute int, relative float64) {
+		var diffs []monitor.PriceDifference
+		if relative > 0 {
+			diffs = append(diffs, monitor.PriceDifference{Pair: pair, ServiceA: "SQS", ServiceB: "CoinGecko", Difference: relative, Rule: rule})
+		}
+		_, err := m.Update(context.Background(), start.Add(time.Duration(minute)*time.Minute), monitor.NewIncidents(diffs, nil, nil, nil))
+		assert.NoError(t, err)
+	}
+
+	cycle(0, 0.015)
+	cycle(1, 0.1) // severity grows, labels stay the same
+	cycle(2, 0)
+	cycle(3, 0) // nothing is pushed without incidents
+
+	assert.Len(t, pushes, 3)
+	labels := map[string]string{
+		"alertname":  "PriceDeviation",
+		"kind":       "deviation",
+		"severity":   "warning",
+		"pair":       "osmo/usd",
+		"base":       "osmo",
+		"quote":      "usd",
+		"provider_a": "SQS",
+		"provider_b": "CoinGecko",
+	}
+
+	for i, push := range pushes {
+		assert.Len(t, push, 1)
+		assert.Equal(t, labels, push[0].Labels)
+		assert.Equal(t, start, push[0].StartsAt.UTC())
+		assert.NotEmpty(t, push[0].Annotations["summary"])
+		if i < 2 {
+			// endsAt is extended by the TTL while firing
+			assert.Equal(t, start.Add(time.Duration(i)*time.Minute+3*time.Minute), push[0].EndsAt.UTC())
+		}
+	}
+	// endsAt is the resolution time once resolved
+	assert.Equal(t, start.Add(2*time.Minute), pushes[2][0].EndsAt.UTC())
+}
+
+func TestAlertmanager_ProviderDown(t *testing.T) {
+	var alerts []alertmanagerAlert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&alerts))
+	}))
+	defer server.Close()
+
+	a := NewAlertmanager(server.URL, time.Minute)
+	incidents := monitor.NewIncidents(nil, nil, nil, []*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}})
+	assert.NoError(t, a.Refresh(context.Background(), time.Now(), incidents))
+
+	assert.Equal(t, map[string]string{
+		"alertname":  "PriceProviderDown",
+		"kind":       "provider_down",
+		"severity":   "error",
+		"provider_a": "SQS",
+	}, alerts[0].Labels)
+}
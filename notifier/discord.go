@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/deividaspetraitis/price-monitor"
+)
+
+// Discord is a monitor.Notifier posting alert events to a Discord webhook.
+// Only firing and resolved events are sent.
+type Discord struct {
+	WebhookURL string
+	Username   string     // Username overrides the name of the webhook
+	Templates  *Templates // Templates, if set, override the built-in messages
+	HTTPClient *http.Client
+}
+
+// NewDiscord creates a new Discord notifier posting to webhookURL.
+func NewDiscord(webhookURL string) *Discord {
+	return &Discord{
+		WebhookURL: webhookURL,
+		Username:   "price-monitor",
+		HTTPClient: &http.Client{},
+	}
+}
+
+// discordMessage is the payload of a Discord webhook.
+type discordMessage struct {
+	Content  string `json:"content"`
+	Username string `json:"username,omitempty"`
+}
+
+// Notify implements monitor.Notifier.
+func (d *Discord) Notify(ctx context.Context, event monitor.Event) error {
+	if event.Type == monitor.Reminder {
+		return nil
+	}
+
+	msg := discordMessage{
+		Content: d.Templates.render(event, func() string {
+			return markdown(event, func(s string) string { return "**" + s + "**" }, escapeDiscord)
+		}),
+		Username: d.Username,
+	}
+	if err := postJSON(ctx, d.HTTPClient, d.WebhookURL, msg); err != nil {
+		// The webhook token is part of the URL, keep it out of logs.
+		return fmt.Errorf("discord: %w", redact(err, d.WebhookURL, "<webhook>"))
+	}
+
+	return nil
+}
+
+// discordReplacer escapes the characters of Discord markdown.
+var discordReplacer = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`)
+
+// escapeDiscord escapes s for Discord markdown.
+func escapeDiscord(s string) string {
+	return discordReplacer.Replace(s)
+}
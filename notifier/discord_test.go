@@ -0,0 +1,60 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscord_Notify(t *testing.T) {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	stale := monitor.StalePrice{
+		PriceData: monitor.PriceData{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "Coin_Gecko"},
+		Age:       10 * time.Minute,
+		Rule:      monitor.Rule{MaxAge: 5 * time.Minute},
+	}
+	incident := monitor.NewIncidents(nil, []monitor.StalePrice{stale}, nil, nil)[0]
+	incident.ID = "abc"
+	incident.StartedAt = start
+	incident.ResolvedAt = start.Add(2 * time.Minute)
+
+	var messages []discordMessage
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/webhooks/1/token", r.URL.Path)
+		var m discordMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		messages = append(messages, m)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	d := NewDiscord(server.URL + "/api/webhooks/1/token")
+
+	for _, typ := range []monitor.EventType{monitor.Firing, monitor.Reminder, monitor.Resolved} {
+		assert.NoError(t, d.Notify(context.Background(), monitor.Event{Type: typ, Incident: incident}))
+	}
+
+	// Reminders are not sent.
+	assert.Len(t, messages, 2)
+	assert.Equal(t, discordMessage{
+		Username: "price-monitor",
+		Content:  "🚨 **Firing**: price of pair osmo/usd from Coin\\_Gecko is stale: 10m0s old, max age 5m0s\nincident abc",
+	}, messages[0])
+	assert.Equal(t, "✅ **Resolved**: price of pair osmo/usd from Coin\\_Gecko is stale: 10m0s old, max age 5m0s\n**Duration**: 2m0s\nincident abc", messages[1].Content)
+
+	status = http.StatusNotFound
+	err := d.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident})
+	assert.EqualError(t, err, "discord: unexpected status code: 404")
+
+	var statusErr *monitor.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+}
@@ -0,0 +1,295 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+)
+
+// Email is a monitor.Notifier sending alert events by email through an SMTP server.
+// By default each event is sent as an email of its own. With a Digest interval, firing and resolved events
+// are collected instead and sent as a single email summarising the window once it has passed.
+// Messages contain a plain text and an HTML alternative rendered from TextTemplate and HTMLTemplate.
+type Email struct {
+	Addr       string // Addr is the host:port of the SMTP server
+	From       string
+	To         []string
+	Username   string // Username, if set, authenticates with PLAIN auth, allowed only over TLS or to localhost
+	Password   string
+	StartTLS   bool        // StartTLS upgrades the connection with STARTTLS, required if set
+	TLSConfig  *tls.Config // TLSConfig configures STARTTLS, by default the server name is the host of Addr
+	Timeout    time.Duration
+	Digest     time.Duration // Digest, if set, is the interval of digests
+	MaxPending int           // MaxPending, if set, caps the pending events of a digest, e.g. during an SMTP outage, the oldest are dropped and counted
+	Templates  *Templates    // Templates, if set, override the built-in subject of emails sent per event
+
+	TextTemplate *template.Template     // TextTemplate renders the plain text body from EmailData
+	HTMLTemplate *htmltemplate.Template // HTMLTemplate renders the HTML body from EmailData
+
+	mu      sync.Mutex
+	pending []monitor.Event // pending are the events of the current digest window
+	dropped int             // dropped is the number of events of the current digest window dropped over MaxPending
+	since   time.Time       // since is the start of the current digest window
+}
+
+// EmailData is the data email templates are executed with.
+type EmailData struct {
+	Subject  string
+	Digest   bool      // Digest indicates whether the email is a digest
+	Since    time.Time // Since is the start of the digest window
+	Until    time.Time // Until is the end of the digest window
+	Firing   int       // Firing is the number of firing events
+	Resolved int       // Resolved is the number of resolved events
+	Dropped  int       // Dropped is the number of older events left out of the digest over Email.MaxPending
+	Events   []monitor.Event
+}
+
+// DefaultEmailText is the default template of plain text email bodies.
+const DefaultEmailText = `{{if .Digest}}Price monitor digest {{.Since.UTC.Format "2006-01-02 15:04"}} - {{.Until.UTC.Format "2006-01-02 15:04 MST"}}
+{{.Firing}} firing, {{.Resolved}} resolved.{{if .Dropped}} {{.Dropped}} older events were dropped.{{end}}
+{{end}}{{range .Events}}
+[{{.Type}}] {{.Incident.Summary}}
+  incident {{.Incident.ID}}, started {{.Incident.StartedAt.UTC.Format "2006-01-02 15:04:05 MST"}}{{if eq .Type "resolved"}}, resolved {{.Incident.ResolvedAt.UTC.Format "2006-01-02 15:04:05 MST"}}{{end}}
+{{end}}`
+
+// DefaultEmailHTML is the default template of HTML email bodies.
+const DefaultEmailHTML = `<html><body>
+{{if .Digest}}<h2>Price monitor digest {{.Since.UTC.Format "2006-01-02 15:04"}} - {{.Until.UTC.Format "2006-01-02 15:04 MST"}}</h2>
+<p>{{.Firing}} firing, {{.Resolved}} resolved.{{if .Dropped}} {{.Dropped}} older events were dropped.{{end}}</p>
+{{end}}<table>
+<tr><th>Event</th><th>Incident</th><th>Summary</th><th>Started</th><th>Resolved</th></tr>
+{{range .Events}}<tr><td>{{.Type}}</td><td>{{.Incident.ID}}</td><td>{{.Incident.Summary}}</td><td>{{.Incident.StartedAt.UTC.Format "2006-01-02 15:04:05 MST"}}</td><td>{{if eq .Type "resolved"}}{{.Incident.ResolvedAt.UTC.Format "2006-01-02 15:04:05 MST"}}{{end}}</td></tr>
+{{end}}</table>
+</body></html>
+`
+
+// NewEmail creates a new Email notifier sending from from to the given recipients through the SMTP server at addr.
+// Digests hold at most 1000 pending events.
+func NewEmail(addr, from string, to []string) *Email {
+	return &Email{
+		Addr:         addr,
+		From:         from,
+		To:           to,
+		Timeout:      30 * time.Second,
+		MaxPending:   1000,
+		TextTemplate: template.Must(template.New("text").Parse(DefaultEmailText)),
+		HTMLTemplate: htmltemplate.Must(htmltemplate.New("html").Parse(DefaultEmailHTML)),
+	}
+}
+
+// Notify implements monitor.Notifier.
+func (e *Email) Notify(ctx context.Context, event monitor.Event) error {
+	if e.Digest > 0 {
+		if event.Type != monitor.Reminder {
+			e.mu.Lock()
+			e.pending = append(e.pending, event)
+			if e.MaxPending > 0 && len(e.pending) > e.MaxPending {
+				n := len(e.pending) - e.MaxPending
+				e.pending = append(e.pending[:0], e.pending[n:]...)
+				e.dropped += n
+			}
+			e.mu.Unlock()
+		}
+		return nil
+	}
+
+	subject := e.Templates.render(event, func() string {
+		return fmt.Sprintf("[price-monitor] %s: %s", strings.ToUpper(string(event.Type)), event.Incident.Summary())
+	})
+	return e.send(ctx, EmailData{Subject: subject, Events: []monitor.Event{event}})
+}
+
+// Refresh implements monitor.Refresher, it sends the digest once its window has passed.
+func (e *Email) Refresh(ctx context.Context, now time.Time, incidents []monitor.Incident) error {
+	if e.Digest <= 0 {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.since.IsZero() {
+		e.since = now
+	}
+	if now.Sub(e.since) < e.Digest {
+		return nil
+	}
+	return e.flush(ctx, now)
+}
+
+// Flush implements monitor.Flusher, it sends the digest of the pending events without waiting for its window to pass.
+func (e *Email) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.since.IsZero() && len(e.pending) > 0 {
+		e.since = e.pending[0].Time
+	}
+	return e.flush(ctx, time.Now())
+}
+
+// flush sends the digest of the pending events and starts a new window at now. The caller must hold the lock.
+func (e *Email) flush(ctx context.Context, now time.Time) error {
+	if len(e.pending) == 0 {
+		e.since = now
+		return nil
+	}
+
+	data := EmailData{Digest: true, Since: e.since, Until: now, Dropped: e.dropped, Events: e.pending}
+	for _, event := range e.pending {
+		switch event.Type {
+		case monitor.Firing:
+			data.Firing++
+		case monitor.Resolved:
+			data.Resolved++
+		}
+	}
+	data.Subject = fmt.Sprintf("[price-monitor] Digest: %d firing, %d resolved", data.Firing, data.Resolved)
+	if data.Dropped > 0 {
+		data.Subject += fmt.Sprintf(", %d dropped", data.Dropped)
+	}
+
+	// Pending events are kept for the next cycle if the digest can't be sent.
+	if err := e.send(ctx, data); err != nil {
+		return err
+	}
+	e.pending, e.dropped, e.since = nil, 0, now
+
+	return nil
+}
+
+// send renders data and sends it as an email.
+func (e *Email) send(ctx context.Context, data EmailData) error {
+	msg, err := e.message(data)
+	if err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	if err := e.deliver(ctx, msg); err != nil {
+		return fmt.Errorf("email: %w", err)
+	}
+	return nil
+}
+
+// message renders data as a multipart MIME message with plain text and HTML alternatives.
+func (e *Email) message(data EmailData) ([]byte, error) {
+	var text, html bytes.Buffer
+	if err := e.TextTemplate.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render text template: %w", err)
+	}
+	if err := e.HTMLTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render html template: %w", err)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(w)
+		if _, err := qw.Write(part.content); err != nil {
+			return nil, err
+		}
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", data.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// deliver sends msg through the SMTP server.
+func (e *Email) deliver(ctx context.Context, msg []byte) error {
+	host, _, err := net.SplitHostPort(e.Addr)
+	if err != nil {
+		return err
+	}
+
+	dialer := net.Dialer{Timeout: e.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", e.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	if e.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(e.Timeout))
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if e.StartTLS {
+		config := e.TLSConfig
+		if config == nil {
+			config = &tls.Config{ServerName: host}
+		}
+		if err := c.StartTLS(config); err != nil {
+			return fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+	if e.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.Username, e.Password, host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := c.Mail(e.From); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
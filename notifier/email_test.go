@@ -0,0 +1,250 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+// smtpMessage is a message received by smtpSink.
+type smtpMessage struct {
+	From string
+	To   []string
+	Auth string // Auth is the decoded PLAIN auth response
+	Data []byte
+}
+
+// smtpSink is a local SMTP server accepting any message.
+type smtpSink struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &smtpSink{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpSink) serve(conn net.Conn) {
+	tc := textproto.NewConn(conn)
+	defer tc.Close()
+
+	var m smtpMessage
+	tc.PrintfLine("220 localhost ESMTP sink")
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		switch strings.ToUpper(fields[0]) {
+		case "EHLO":
+			tc.PrintfLine("250-localhost")
+			tc.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			auth, _ := base64.StdEncoding.DecodeString(fields[2])
+			m.Auth = string(auth)
+			tc.PrintfLine("235 2.7.0 Authentication successful")
+		case "MAIL":
+			m.From = strings.Trim(strings.TrimPrefix(fields[1], "FROM:"), "<>")
+			tc.PrintfLine("250 OK")
+		case "RCPT":
+			m.To = append(m.To, strings.Trim(strings.TrimPrefix(fields[1], "TO:"), "<>"))
+			tc.PrintfLine("250 OK")
+		case "DATA":
+			tc.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			m.Data, _ = tc.ReadDotBytes()
+			s.mu.Lock()
+			s.messages = append(s.messages, m)
+			s.mu.Unlock()
+			tc.PrintfLine("250 OK")
+		case "QUIT":
+			tc.PrintfLine("221 Bye")
+			return
+		default:
+			tc.PrintfLine("250 OK")
+		}
+	}
+}
+
+func (s *smtpSink) Messages() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messages
+}
+
+// parseEmail returns the subject and the plain text and HTML bodies of an email.
+func parseEmail(t *testing.T, data []byte) (subject, text, html string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, _ = new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(part)
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/html") {
+			html = string(body)
+		} else {
+			text = string(body)
+		}
+	}
+	return subject, text, html
+}
+
+func TestEmail_Notify(t *testing.T) {
+	sink := newSMTPSink(t)
+
+	e := NewEmail(sink.ln.Addr().String(), "monitor@example.com", []string{"ops@example.com", "dev@example.com"})
+	e.Username, e.Password = "user", "secret"
+
+	incident := monitor.NewIncidents(nil, nil, nil, []*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}})[0]
+	incident.ID = "abc"
+	incident.StartedAt = time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	assert.NoError(t, e.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident, Time: incident.StartedAt}))
+
+	messages := sink.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "monitor@example.com", messages[0].From)
+	assert.Equal(t, []string{"ops@example.com", "dev@example.com"}, messages[0].To)
+	assert.Equal(t, "\x00user\x00secret", messages[0].Auth)
+
+	subject, text, html := parseEmail(t, messages[0].Data)
+	assert.Equal(t, "[price-monitor] FIRING: provider SQS is down: context deadline exceeded", subject)
+	assert.Equal(t, "\n[firing] provider SQS is down: context deadline exceeded\n  incident abc, started 2024-12-01 10:00:00 UTC\n", text)
+	assert.Contains(t, html, "<td>provider SQS is down: context deadline exceeded</td>")
+}
+
+func TestEmail_Digest(t *testing.T) {
+	sink := newSMTPSink(t)
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	e := NewEmail(sink.ln.Addr().String(), "monitor@example.com", []string{"ops@example.com"})
+	e.Digest = time.Hour
+
+	m := monitor.NewAlertManager(10*time.Minute, e)
+	down := monitor.NewIncidents(nil, nil, nil, []*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}})
+	stale := monitor.NewIncidents(nil, []monitor.StalePrice{{PriceData: monitor.PriceData{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "CoinGecko"}, Age: 10 * time.Minute}}, nil, nil)
+	for _, cycle := range []struct {
+		minute     int
+		conditions []monitor.Incident
+	}{
+		{0, down},
+		{20, []monitor.Incident{down[0], stale[0]}}, // reminder of down is not part of the digest
+		{40, stale},
+	} {
+		_, err := m.Update(context.Background(), start.Add(time.Duration(cycle.minute)*time.Minute), cycle.conditions)
+		assert.NoError(t, err)
+	}
+	assert.Empty(t, sink.Messages())
+
+	_, err := m.Update(context.Background(), start.Add(time.Hour), stale)
+	assert.NoError(t, err)
+	assert.Len(t, sink.Messages(), 1)
+
+	subject, text, _ := parseEmail(t, sink.Messages()[0].Data)
+	assert.Equal(t, "[price-monitor] Digest: 2 firing, 1 resolved", subject)
+	assert.Contains(t, text, "Price monitor digest 2024-12-01 10:00 - 2024-12-01 11:00 UTC\n2 firing, 1 resolved.\n")
+	assert.Contains(t, text, "[resolved] provider SQS is down")
+
+	// Empty windows are not sent.
+	_, err = m.Update(context.Background(), start.Add(2*time.Hour), stale)
+	assert.NoError(t, err)
+	assert.Len(t, sink.Messages(), 1)
+
+	// Flushing sends pending events before the window has passed.
+	_, err = m.Update(context.Background(), start.Add(2*time.Hour+time.Minute), nil)
+	assert.NoError(t, err)
+	assert.Len(t, sink.Messages(), 1)
+	assert.NoError(t, e.Flush(context.Background()))
+	assert.Len(t, sink.Messages(), 2)
+
+	subject, _, _ = parseEmail(t, sink.Messages()[1].Data)
+	assert.Equal(t, "[price-monitor] Digest: 0 firing, 1 resolved", subject)
+	assert.NoError(t, e.Flush(context.Background()))
+	assert.Len(t, sink.Messages(), 2)
+}
+
+func TestEmail_DigestMaxPending(t *testing.T) {
+	sink := newSMTPSink(t)
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+
+	e := NewEmail("127.0.0.1:1", "monitor@example.com", []string{"ops@example.com"})
+	e.Digest = time.Hour
+	e.MaxPending = 2
+
+	incident := monitor.NewIncidents(nil, nil, nil, []*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}})[0]
+	notify := func(minute int, typ monitor.EventType) {
+		incident.ID = fmt.Sprintf("id%d", minute)
+		assert.NoError(t, e.Notify(context.Background(), monitor.Event{Type: typ, Incident: incident, Time: start.Add(time.Duration(minute) * time.Minute)}))
+	}
+
+	// The SMTP server is unreachable, the oldest events are dropped over MaxPending.
+	assert.NoError(t, e.Refresh(context.Background(), start, nil))
+	notify(0, monitor.Firing)
+	notify(1, monitor.Resolved)
+	assert.Error(t, e.Refresh(context.Background(), start.Add(time.Hour), nil))
+	notify(61, monitor.Firing)
+	notify(62, monitor.Resolved)
+
+	e.Addr = sink.ln.Addr().String()
+	assert.NoError(t, e.Refresh(context.Background(), start.Add(2*time.Hour), nil))
+	assert.Len(t, sink.Messages(), 1)
+
+	subject, text, _ := parseEmail(t, sink.Messages()[0].Data)
+	assert.Equal(t, "[price-monitor] Digest: 1 firing, 1 resolved, 2 dropped", subject)
+	assert.Contains(t, text, "1 firing, 1 resolved. 2 older events were dropped.\n")
+	assert.Contains(t, text, "incident id61")
+	assert.NotContains(t, text, "incident id0,")
+
+	// The count of dropped events is reset with the window.
+	notify(121, monitor.Firing)
+	assert.NoError(t, e.Flush(context.Background()))
+	subject, _, _ = parseEmail(t, sink.Messages()[1].Data)
+	assert.Equal(t, "[price-monitor] Digest: 1 firing, 0 resolved", subject)
+}
@@ -0,0 +1,42 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/log"
+)
+
+// Log is a monitor.Notifier writing alert events to a logger.
+type Log struct {
+	Logger    log.Logger
+	Templates *Templates // Templates, if set, override the built-in messages
+}
+
+// NewLog creates a new Log notifier writing to logger.
+func NewLog(logger log.Logger) *Log {
+	return &Log{Logger: logger}
+}
+
+// Notify implements monitor.Notifier.
+func (l *Log) Notify(ctx context.Context, event monitor.Event) error {
+	i := event.Incident
+	msg := l.Templates.render(event, func() string {
+		switch event.Type {
+		case monitor.Resolved:
+			return fmt.Sprintf("Resolved [%s] %s: %s, open for %s", i.ID, i.Kind, i.Summary(), i.ResolvedAt.Sub(i.StartedAt).Round(time.Second))
+		case monitor.Reminder:
+			return fmt.Sprintf("Still firing [%s] %s: %s, open for %s", i.ID, i.Kind, i.Summary(), event.Time.Sub(i.StartedAt).Round(time.Second))
+		}
+		return fmt.Sprintf("Firing [%s] %s: %s", i.ID, i.Kind, i.Summary())
+	})
+
+	if event.Type == monitor.Resolved {
+		l.Logger.Info(msg)
+	} else {
+		l.Logger.Error(msg)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+)
+
+// markdown formats the event as a chat message. bold formats text in bold and escape escapes text
+// in the markdown dialect of the chat.
+func markdown(event monitor.Event, bold, escape func(string) string) string {
+	i := event.Incident
+
+	var title string
+	switch event.Type {
+	case monitor.Resolved:
+		title = "✅ " + bold("Resolved")
+	case monitor.Reminder:
+		title = "⚠️ " + bold("Still firing")
+	default:
+		title = "🚨 " + bold("Firing")
+	}
+
+	var lines []string
+	if d := i.Difference; d != nil {
+		lines = []string{
+			title + escape(fmt.Sprintf(": price difference for %s", d.Pair)),
+			bold(escape(d.ServiceA)) + escape(fmt.Sprintf(": %v", d.PriceA)),
+			bold(escape(d.ServiceB)) + escape(fmt.Sprintf(": %v", d.PriceB)),
+			bold("Difference") + escape(fmt.Sprintf(": %.4f (%.2f%%), %s threshold %v", d.Difference, d.Relative*100, d.Rule.Mode, d.Rule.Threshold)),
+		}
+	} else {
+		lines = []string{title + escape(": "+i.Summary())}
+	}
+
+	if event.Type == monitor.Resolved {
+		lines = append(lines, bold("Duration")+escape(": "+i.ResolvedAt.Sub(i.StartedAt).Round(time.Second).String()))
+	}
+	lines = append(lines, escape("incident "+i.ID))
+
+	return strings.Join(lines, "\n")
+}
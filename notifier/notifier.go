@@ -0,0 +1,171 @@
+// Package notifier implements monitor.Notifier clients delivering alert events to notification channels.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+)
+
+// postJSON sends v encoded as JSON to url and fails on a non 2xx response.
+func postJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	return post(ctx, client, url, body, nil)
+}
+
+// post sends the JSON body to url with the given headers and fails on a non 2xx response.
+func post(ctx context.Context, client *http.Client, url string, body []byte, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &monitor.StatusError{StatusCode: resp.StatusCode}
+	}
+
+	return nil
+}
+
+// redactedError is an error whose message has a secret, e.g. a token part of the request URL, replaced.
+// The error is kept wrapped, so that e.g. a *monitor.StatusError can still be inspected.
+type redactedError struct {
+	err         error
+	secret      string
+	replacement string
+}
+
+// redact returns err with secret replaced by replacement in its message.
+func redact(err error, secret, replacement string) error {
+	return &redactedError{err: err, secret: secret, replacement: replacement}
+}
+
+func (e *redactedError) Error() string {
+	if e.secret == "" {
+		return e.err.Error()
+	}
+	return strings.ReplaceAll(e.err.Error(), e.secret, e.replacement)
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// List of incident severities, named after PagerDuty severities.
+const (
+	severityCritical = "critical"
+	severityError    = "error"
+	severityWarning  = "warning"
+)
+
+// severity returns the severity of an incident. Deviations are graded by how far they exceed the threshold,
+// provider outages and missing consensus are errors and stale quotes are warnings.
+func severity(i monitor.Incident) string {
+	switch i.Kind {
+	case monitor.DeviationIncident:
+		if i.Difference == nil {
+			return severityError
+		}
+		switch e := i.Difference.Exceedance(); {
+		case e >= 5:
+			return severityCritical
+		case e >= 2:
+			return severityError
+		}
+		return severityWarning
+	case monitor.StaleIncident:
+		return severityWarning
+	}
+	return severityError
+}
+
+// limiter rate limits notifications so that a flapping condition can't flood a channel.
+// An incident fires at most once per interval for the same condition, reminders and resolutions
+// of incidents whose firing was suppressed are suppressed as well, until their firing is released.
+type limiter struct {
+	interval time.Duration
+
+	mu         sync.Mutex
+	fired      map[string]time.Time // fired holds the time of the last delivered firing per incident key
+	suppressed map[string]bool      // suppressed holds the ids of incidents whose firing was suppressed
+}
+
+// newLimiter creates a limiter allowing one firing per condition per interval, zero disables rate limiting.
+func newLimiter(interval time.Duration) *limiter {
+	return &limiter{
+		interval:   interval,
+		fired:      make(map[string]time.Time),
+		suppressed: make(map[string]bool),
+	}
+}
+
+// allow reports whether the event should be delivered.
+func (l *limiter) allow(event monitor.Event) bool {
+	if l.interval <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	i := event.Incident
+	switch event.Type {
+	case monitor.Firing:
+		if last, ok := l.fired[i.Key]; ok && event.Time.Sub(last) < l.interval {
+			l.suppressed[i.ID] = true
+			return false
+		}
+		l.fired[i.Key] = event.Time
+		return true
+
+	case monitor.Resolved:
+		suppressed := l.suppressed[i.ID]
+		delete(l.suppressed, i.ID)
+		return !suppressed
+	}
+
+	return !l.suppressed[i.ID]
+}
+
+// release returns the open incidents whose firing was suppressed and may be delivered at now, as the
+// interval has passed since the last delivered firing of their condition. Their firing counts as delivered.
+func (l *limiter) release(now time.Time, incidents []monitor.Incident) []monitor.Incident {
+	if l.interval <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var released []monitor.Incident
+	for _, i := range incidents {
+		if !l.suppressed[i.ID] || now.Sub(l.fired[i.Key]) < l.interval {
+			continue
+		}
+		delete(l.suppressed, i.ID)
+		l.fired[i.Key] = now
+		released = append(released, i)
+	}
+	return released
+}
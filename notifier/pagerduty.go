@@ -0,0 +1,144 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+)
+
+// PagerDutySource is the source of events sent to PagerDuty.
+const PagerDutySource = "price-monitor"
+
+// PagerDuty is a monitor.Notifier sending alert events to the PagerDuty Events API v2.
+// Firing incidents trigger an alert and resolved incidents resolve it, reminders are not sent
+// as PagerDuty keeps the alert open until it is resolved.
+type PagerDuty struct {
+	URL        string
+	RoutingKey string     // RoutingKey is the integration key of the PagerDuty service
+	Templates  *Templates // Templates, if set, override the built-in alert summary
+	HTTPClient *http.Client
+}
+
+// NewPagerDuty creates a new PagerDuty notifier sending events with routingKey.
+func NewPagerDuty(routingKey string) *PagerDuty {
+	return &PagerDuty{
+		URL:        "https://events.pagerduty.com/v2/enqueue",
+		RoutingKey: routingKey,
+		HTTPClient: &http.Client{},
+	}
+}
+
+// pagerDutyEvent is a PagerDuty Events API v2 event.
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp"`
+	Component     string         `json:"component,omitempty"`
+	Class         string         `json:"class"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+// Notify implements monitor.Notifier.
+func (p *PagerDuty) Notify(ctx context.Context, event monitor.Event) error {
+	i := event.Incident
+
+	e := pagerDutyEvent{
+		RoutingKey: p.RoutingKey,
+		DedupKey:   dedupKey(i),
+	}
+	switch event.Type {
+	case monitor.Firing:
+		e.EventAction = "trigger"
+		e.Payload = &pagerDutyPayload{
+			Summary:       p.Templates.render(event, i.Summary),
+			Source:        PagerDutySource,
+			Severity:      severity(i),
+			Timestamp:     i.StartedAt.UTC().Format(time.RFC3339),
+			Component:     i.Pair.String(),
+			Class:         string(i.Kind),
+			CustomDetails: details(i),
+		}
+		if i.Pair == (monitor.Pair{}) {
+			e.Payload.Component = strings.Join(i.Providers, ",")
+		}
+	case monitor.Resolved:
+		e.EventAction = "resolve"
+	default:
+		return nil
+	}
+
+	if err := postJSON(ctx, p.HTTPClient, p.URL, e); err != nil {
+		return fmt.Errorf("pagerduty: %w", err)
+	}
+
+	return nil
+}
+
+// dedupKey returns a key identifying the condition of the incident by its kind, pair and set of providers,
+// it is stable across restarts and independent of the order of providers. The providers of no consensus
+// incidents are those which answered, they change while the incident is open and are left out.
+func dedupKey(i monitor.Incident) string {
+	parts := []string{PagerDutySource, string(i.Kind)}
+	if i.Pair != (monitor.Pair{}) {
+		parts = append(parts, i.Pair.String())
+	}
+	if i.Kind != monitor.NoConsensusIncident {
+		providers := slices.Clone(i.Providers)
+		slices.Sort(providers)
+		parts = append(parts, strings.Join(providers, ","))
+	}
+	return strings.Join(parts, ":")
+}
+
+// details returns the details of the condition of the incident.
+func details(i monitor.Incident) map[string]any {
+	d := map[string]any{
+		"incident_id": i.ID,
+		"kind":        i.Kind,
+		"providers":   i.Providers,
+		"started_at":  i.StartedAt.UTC().Format(time.RFC3339),
+	}
+	if i.Pair != (monitor.Pair{}) {
+		d["pair"] = i.Pair.String()
+	}
+
+	switch {
+	case i.Difference != nil:
+		diff := i.Difference
+		d["service_a"] = diff.ServiceA
+		d["service_b"] = diff.ServiceB
+		d["price_a"] = monitor.Number(diff.PriceA)
+		d["price_b"] = monitor.Number(diff.PriceB)
+		d["difference"] = monitor.Number(diff.Difference)
+		d["relative"] = monitor.Number(diff.Relative)
+		d["direction"] = diff.Direction
+		d["threshold"] = diff.Rule.Threshold
+		d["threshold_mode"] = diff.Rule.Mode
+		d["strategy"] = diff.Rule.Strategy
+		d["exceedance"] = monitor.Number(diff.Exceedance())
+	case i.Stale != nil:
+		d["price"] = monitor.Number(i.Stale.Price)
+		d["updated_at"] = i.Stale.UpdatedAt.UTC().Format(time.RFC3339)
+		d["age"] = i.Stale.Age.String()
+		d["max_age"] = i.Stale.Rule.MaxAge.String()
+	case i.NoConsensus != nil:
+		d["quorum"] = i.NoConsensus.Quorum
+	case i.Failure != nil:
+		d["error"] = i.Failure.Err.Error()
+	}
+
+	return d
+}
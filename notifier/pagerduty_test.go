@@ -0,0 +1,145 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPagerDuty_Notify(t *testing.T) {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	diff := monitor.PriceDifference{
+		Pair:       monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD},
+		ServiceA:   "SQS",
+		ServiceB:   "CoinGecko",
+		PriceA:     0.52,
+		PriceB:     0.5,
+		Difference: 0.02,
+		Relative:   0.04,
+		Direction:  monitor.Over,
+		Rule:       monitor.Rule{Threshold: 1, Mode: monitor.Percent, Strategy: monitor.Pairwise},
+	}
+	incident := monitor.NewIncidents([]monitor.PriceDifference{diff}, nil, nil, nil)[0]
+	incident.ID = "abc"
+	incident.StartedAt = start
+
+	var events []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&e))
+		events = append(events, e)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	p := NewPagerDuty("routing-key")
+	p.URL = server.URL
+
+	assert.NoError(t, p.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident, Time: start}))
+	assert.NoError(t, p.Notify(context.Background(), monitor.Event{Type: monitor.Reminder, Incident: incident, Time: start.Add(time.Hour)}))
+	assert.NoError(t, p.Notify(context.Background(), monitor.Event{Type: monitor.Resolved, Incident: incident, Time: start.Add(2 * time.Hour)}))
+
+	assert.Len(t, events, 2)
+	assert.Equal(t, map[string]any{
+		"routing_key":  "routing-key",
+		"event_action": "trigger",
+		"dedup_key":    "price-monitor:deviation:osmo/usd:CoinGecko,SQS",
+		"payload": map[string]any{
+			"summary":   incident.Summary(),
+			"source":    "price-monitor",
+			"severity":  "error",
+			"timestamp": "2024-12-01T10:00:00Z",
+			"component": "osmo/usd",
+			"class":     "deviation",
+			"custom_details": map[string]any{
+				"incident_id":    "abc",
+				"kind":           "deviation",
+				"pair":           "osmo/usd",
+				"providers":      []any{"SQS", "CoinGecko"},
+				"started_at":     "2024-12-01T10:00:00Z",
+				"service_a":      "SQS",
+				"service_b":      "CoinGecko",
+				"price_a":        0.52,
+				"price_b":        0.5,
+				"difference":     0.02,
+				"relative":       0.04,
+				"direction":      "over",
+				"threshold":      1.0,
+				"threshold_mode": "percent",
+				"strategy":       "pairwise",
+				"exceedance":     4.0,
+			},
+		},
+	}, events[0])
+	assert.Equal(t, map[string]any{
+		"routing_key":  "routing-key",
+		"event_action": "resolve",
+		"dedup_key":    "price-monitor:deviation:osmo/usd:CoinGecko,SQS",
+	}, events[1])
+}
+
+func TestPagerDuty_DedupKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e struct {
+			DedupKey string `json:"dedup_key"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&e))
+		keys = append(keys, e.DedupKey)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	p := NewPagerDuty("routing-key")
+	p.URL = server.URL
+	m := monitor.NewAlertManager(0, p)
+
+	// Providers answering change while the pair has no consensus, the incident is resolved with the key it was triggered with.
+	pair := monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	for i, conditions := range [][]monitor.Incident{
+		monitor.NewIncidents(nil, nil, []monitor.NoConsensus{{Pair: pair, Providers: []string{"SQS", "CoinGecko"}, Quorum: 3}}, nil),
+		monitor.NewIncidents(nil, nil, []monitor.NoConsensus{{Pair: pair, Providers: []string{"SQS"}, Quorum: 3}}, nil),
+		nil,
+	} {
+		_, err := m.Update(context.Background(), start.Add(time.Duration(i)*time.Minute), conditions)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"price-monitor:no_consensus:osmo/usd", "price-monitor:no_consensus:osmo/usd"}, keys)
+}
+
+func TestPagerDuty_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	p := NewPagerDuty("routing-key")
+	p.URL = server.URL
+
+	incident := monitor.NewIncidents(nil, nil, nil, []*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}})[0]
+	err := p.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident})
+	assert.EqualError(t, err, "pagerduty: unexpected status code: 400")
+}
+
+func TestSeverity(t *testing.T) {
+	rule := monitor.Rule{Threshold: 10, Mode: monitor.BasisPoints}
+	deviation := func(relative float64) monitor.Incident {
+		return monitor.NewIncidents([]monitor.PriceDifference{{Relative: relative, Rule: rule}}, nil, nil, nil)[0]
+	}
+
+	assert.Equal(t, "warning", severity(deviation(0.0015)))
+	assert.Equal(t, "error", severity(deviation(0.002)))
+	assert.Equal(t, "critical", severity(deviation(0.005)))
+	assert.Equal(t, "critical", severity(deviation(math.Inf(1))))
+	assert.Equal(t, "warning", severity(monitor.Incident{Kind: monitor.StaleIncident}))
+	assert.Equal(t, "error", severity(monitor.Incident{Kind: monitor.ProviderDownIncident}))
+}
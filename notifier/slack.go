@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+)
+
+// Slack is a monitor.Notifier posting alert events to a Slack incoming webhook.
+type Slack struct {
+	WebhookURL string
+	MetricsURL string     // MetricsURL, if set, is linked from messages
+	Templates  *Templates // Templates, if set, override the built-in message text
+	HTTPClient *http.Client
+
+	limiter *limiter
+}
+
+// NewSlack creates a new Slack notifier posting to webhookURL.
+// A condition fires at most once per rateLimit, zero disables rate limiting.
+func NewSlack(webhookURL, metricsURL string, rateLimit time.Duration) *Slack {
+	return &Slack{
+		WebhookURL: webhookURL,
+		MetricsURL: metricsURL,
+		HTTPClient: &http.Client{},
+		limiter:    newLimiter(rateLimit),
+	}
+}
+
+// slackMessage is the payload of a Slack incoming webhook.
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Fields []slackField `json:"fields,omitempty"`
+	Footer string       `json:"footer,omitempty"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// Notify implements monitor.Notifier.
+func (s *Slack) Notify(ctx context.Context, event monitor.Event) error {
+	if !s.limiter.allow(event) {
+		return nil
+	}
+
+	if err := postJSON(ctx, s.HTTPClient, s.WebhookURL, s.message(event)); err != nil {
+		// The webhook URL is the credential, keep it out of logs.
+		return fmt.Errorf("slack: %w", redact(err, s.WebhookURL, "<webhook>"))
+	}
+
+	return nil
+}
+
+// Refresh implements monitor.Refresher, it announces incidents whose firing was suppressed by the rate limit
+// once the rate limit allows it, so that a condition which flapped and then persisted isn't left resolved.
+func (s *Slack) Refresh(ctx context.Context, now time.Time, incidents []monitor.Incident) error {
+	for _, i := range s.limiter.release(now, incidents) {
+		if err := postJSON(ctx, s.HTTPClient, s.WebhookURL, s.message(monitor.Event{Type: monitor.Firing, Incident: i, Time: now})); err != nil {
+			return fmt.Errorf("slack: %w", redact(err, s.WebhookURL, "<webhook>"))
+		}
+	}
+
+	return nil
+}
+
+// message formats event as a Slack message.
+func (s *Slack) message(event monitor.Event) slackMessage {
+	i := event.Incident
+
+	var title, color string
+	switch event.Type {
+	case monitor.Resolved:
+		title, color = ":white_check_mark: *Resolved*", "good"
+	case monitor.Reminder:
+		title, color = ":warning: *Still firing*", "warning"
+	default:
+		title, color = ":rotating_light: *Firing*", "danger"
+	}
+
+	var fields []slackField
+	if d := i.Difference; d != nil {
+		title += fmt.Sprintf(": price difference for %s between %s and %s", d.Pair, d.ServiceA, d.ServiceB)
+		fields = []slackField{
+			{Title: "Pair", Value: d.Pair.String(), Short: true},
+			{Title: "Providers", Value: d.ServiceA + " / " + d.ServiceB, Short: true},
+			{Title: d.ServiceA, Value: fmt.Sprintf("%v", d.PriceA), Short: true},
+			{Title: d.ServiceB, Value: fmt.Sprintf("%v", d.PriceB), Short: true},
+			{Title: "Difference", Value: fmt.Sprintf("%.4f (%.2f%%)", d.Difference, d.Relative*100), Short: true},
+			{Title: "Threshold", Value: fmt.Sprintf("%v %s", d.Rule.Threshold, d.Rule.Mode), Short: true},
+		}
+	} else {
+		title += ": " + i.Summary()
+		if i.Pair != (monitor.Pair{}) {
+			fields = append(fields, slackField{Title: "Pair", Value: i.Pair.String(), Short: true})
+		}
+		fields = append(fields, slackField{Title: "Providers", Value: strings.Join(i.Providers, " / "), Short: true})
+	}
+
+	if event.Type == monitor.Resolved {
+		fields = append(fields, slackField{Title: "Duration", Value: i.ResolvedAt.Sub(i.StartedAt).Round(time.Second).String(), Short: true})
+	}
+
+	title = s.Templates.render(event, func() string { return title })
+
+	footer := "incident " + i.ID
+	if s.MetricsURL != "" {
+		footer += fmt.Sprintf(" | <%s|metrics>", s.MetricsURL)
+	}
+
+	return slackMessage{
+		Text:        title,
+		Attachments: []slackAttachment{{Color: color, Fields: fields, Footer: footer}},
+	}
+}
@@ -0,0 +1,150 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlack_Notify(t *testing.T) {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	diff := monitor.PriceDifference{
+		Pair:       monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD},
+		ServiceA:   "SQS",
+		ServiceB:   "CoinGecko",
+		PriceA:     0.52,
+		PriceB:     0.5,
+		Difference: 0.02,
+		Relative:   0.04,
+		Rule:       monitor.Rule{Threshold: 0.01, Mode: monitor.Absolute},
+	}
+	incident := monitor.NewIncidents([]monitor.PriceDifference{diff}, nil, nil, nil)[0]
+	incident.ID = "abc"
+	incident.StartedAt = start
+
+	var messages []slackMessage
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m slackMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		messages = append(messages, m)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s := NewSlack(server.URL, "http://monitord:8080/metrics", 0)
+
+	assert.NoError(t, s.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident, Time: start}))
+	assert.Len(t, messages, 1)
+	assert.Equal(t, ":rotating_light: *Firing*: price difference for osmo/usd between SQS and CoinGecko", messages[0].Text)
+	assert.Equal(t, slackAttachment{
+		Color: "danger",
+		Fields: []slackField{
+			{Title: "Pair", Value: "osmo/usd", Short: true},
+			{Title: "Providers", Value: "SQS / CoinGecko", Short: true},
+			{Title: "SQS", Value: "0.52", Short: true},
+			{Title: "CoinGecko", Value: "0.5", Short: true},
+			{Title: "Difference", Value: "0.0200 (4.00%)", Short: true},
+			{Title: "Threshold", Value: "0.01 absolute", Short: true},
+		},
+		Footer: "incident abc | <http://monitord:8080/metrics|metrics>",
+	}, messages[0].Attachments[0])
+
+	incident.ResolvedAt = start.Add(90 * time.Second)
+	assert.NoError(t, s.Notify(context.Background(), monitor.Event{Type: monitor.Resolved, Incident: incident, Time: incident.ResolvedAt}))
+	assert.Len(t, messages, 2)
+	assert.Equal(t, ":white_check_mark: *Resolved*: price difference for osmo/usd between SQS and CoinGecko", messages[1].Text)
+	assert.Equal(t, "good", messages[1].Attachments[0].Color)
+	assert.Contains(t, messages[1].Attachments[0].Fields, slackField{Title: "Duration", Value: "1m30s", Short: true})
+
+	status = http.StatusForbidden
+	err := s.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident, Time: start})
+	assert.EqualError(t, err, "slack: unexpected status code: 403")
+}
+
+func TestSlack_RateLimit(t *testing.T) {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	failure := &monitor.ProviderError{Provider: "SQS", Err: context.DeadlineExceeded}
+
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m slackMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		texts = append(texts, m.Text)
+	}))
+	defer server.Close()
+
+	s := NewSlack(server.URL, "", 10*time.Minute)
+	notify := func(typ monitor.EventType, id string, minute int) {
+		incident := monitor.NewIncidents(nil, nil, nil, []*monitor.ProviderError{failure})[0]
+		incident.ID = id
+		assert.NoError(t, s.Notify(context.Background(), monitor.Event{Type: typ, Incident: incident, Time: start.Add(time.Duration(minute) * time.Minute)}))
+	}
+
+	// A flapping provider is announced once per rate limit interval.
+	notify(monitor.Firing, "1", 0)
+	notify(monitor.Resolved, "1", 1)
+	notify(monitor.Firing, "2", 2)
+	notify(monitor.Reminder, "2", 3)
+	notify(monitor.Resolved, "2", 4)
+	notify(monitor.Firing, "3", 10)
+
+	assert.Equal(t, []string{
+		":rotating_light: *Firing*: provider SQS is down: context deadline exceeded",
+		":white_check_mark: *Resolved*: provider SQS is down: context deadline exceeded",
+		":rotating_light: *Firing*: provider SQS is down: context deadline exceeded",
+	}, texts)
+}
+
+func TestSlack_RateLimitPersisting(t *testing.T) {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	down := monitor.NewIncidents(nil, nil, nil, []*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}})
+
+	var texts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m slackMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		texts = append(texts, m.Text)
+	}))
+	defer server.Close()
+
+	s := NewSlack(server.URL, "", time.Hour)
+	m := monitor.NewAlertManager(10*time.Minute, s)
+
+	// The provider blips, then stays down.
+	cycles := [][]monitor.Incident{down, nil}
+	for minute := 2; minute <= 70; minute++ {
+		cycles = append(cycles, down)
+	}
+	for minute, conditions := range cycles {
+		_, err := m.Update(context.Background(), start.Add(time.Duration(minute)*time.Minute), conditions)
+		assert.NoError(t, err)
+	}
+
+	// The suppressed firing is delivered once the rate limit passed, followed by reminders.
+	assert.Equal(t, []string{
+		":rotating_light: *Firing*: provider SQS is down: context deadline exceeded",
+		":white_check_mark: *Resolved*: provider SQS is down: context deadline exceeded",
+		":rotating_light: *Firing*: provider SQS is down: context deadline exceeded",
+		":warning: *Still firing*: provider SQS is down: context deadline exceeded",
+	}, texts)
+}
+
+func TestSlack_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close() // requests fail with the URL in the error
+
+	s := NewSlack(server.URL+"/services/T000/B000/SECRET", "", 0)
+
+	incident := monitor.NewIncidents(nil, nil, nil, []*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}})[0]
+	err := s.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident})
+	assert.ErrorContains(t, err, `"<webhook>"`)
+	assert.NotContains(t, err.Error(), "SECRET")
+}
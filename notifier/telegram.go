@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/deividaspetraitis/price-monitor"
+)
+
+// Telegram is a monitor.Notifier sending alert events to a Telegram chat through the Bot API.
+// Only firing and resolved events are sent.
+type Telegram struct {
+	BaseURL    string
+	Token      string     // Token is the bot token
+	ChatID     string     // ChatID is the identifier of the chat, or the @username of the channel
+	Templates  *Templates // Templates, if set, override the built-in messages, rendered text is sent as plain text
+	HTTPClient *http.Client
+}
+
+// NewTelegram creates a new Telegram notifier sending messages to chatID as the bot identified by token.
+func NewTelegram(token, chatID string) *Telegram {
+	return &Telegram{
+		BaseURL:    "https://api.telegram.org",
+		Token:      token,
+		ChatID:     chatID,
+		HTTPClient: &http.Client{},
+	}
+}
+
+// telegramMessage is the request of the Bot API sendMessage method.
+type telegramMessage struct {
+	ChatID    string `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode,omitempty"`
+}
+
+// Notify implements monitor.Notifier.
+func (t *Telegram) Notify(ctx context.Context, event monitor.Event) error {
+	if event.Type == monitor.Reminder {
+		return nil
+	}
+
+	msg := telegramMessage{
+		ChatID:    t.ChatID,
+		Text:      markdown(event, func(s string) string { return "*" + s + "*" }, escapeTelegram),
+		ParseMode: "MarkdownV2",
+	}
+	// Rendered text isn't parsed, as templates are shared with other notifiers and reserved
+	// characters, e.g. the underscores of incident kinds, would make Telegram reject the message.
+	if text, ok := t.Templates.execute(event); ok {
+		msg.Text, msg.ParseMode = text, ""
+	}
+	url := fmt.Sprintf("%s/bot%s/sendMessage", t.BaseURL, t.Token)
+	if err := postJSON(ctx, t.HTTPClient, url, msg); err != nil {
+		// The token is part of the URL, keep it out of logs.
+		return fmt.Errorf("telegram: %w", redact(err, t.Token, "<token>"))
+	}
+
+	return nil
+}
+
+// telegramReplacer escapes the characters reserved by Telegram MarkdownV2.
+var telegramReplacer = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+	">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+// escapeTelegram escapes s for Telegram MarkdownV2.
+func escapeTelegram(s string) string {
+	return telegramReplacer.Replace(s)
+}
@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTelegram_Notify(t *testing.T) {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	diff := monitor.PriceDifference{
+		Pair:       monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD},
+		ServiceA:   "SQS",
+		ServiceB:   "CoinGecko",
+		PriceA:     0.52,
+		PriceB:     0.5,
+		Difference: 0.02,
+		Relative:   0.04,
+		Rule:       monitor.Rule{Threshold: 0.01, Mode: monitor.Absolute},
+	}
+	incident := monitor.NewIncidents([]monitor.PriceDifference{diff}, nil, nil, nil)[0]
+	incident.ID = "abc"
+	incident.StartedAt = start
+	incident.ResolvedAt = start.Add(time.Minute)
+
+	var messages []telegramMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/bot123:token/sendMessage", r.URL.Path)
+		var m telegramMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		messages = append(messages, m)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	tg := NewTelegram("123:token", "-100")
+	tg.BaseURL = server.URL
+
+	for _, typ := range []monitor.EventType{monitor.Firing, monitor.Reminder, monitor.Resolved} {
+		assert.NoError(t, tg.Notify(context.Background(), monitor.Event{Type: typ, Incident: incident}))
+	}
+
+	// Reminders are not sent.
+	assert.Len(t, messages, 2)
+	assert.Equal(t, telegramMessage{
+		ChatID:    "-100",
+		ParseMode: "MarkdownV2",
+		Text: "🚨 *Firing*: price difference for osmo/usd\n" +
+			"*SQS*: 0\\.52\n" +
+			"*CoinGecko*: 0\\.5\n" +
+			"*Difference*: 0\\.0200 \\(4\\.00%\\), absolute threshold 0\\.01\n" +
+			"incident abc",
+	}, messages[0])
+	assert.Contains(t, messages[1].Text, "✅ *Resolved*: price difference for osmo/usd\n")
+	assert.Contains(t, messages[1].Text, "*Duration*: 1m0s\n")
+
+	// Templates are sent as plain text.
+	templates, err := NewTemplates(map[string]string{"firing": "{{.Incident.Kind}} on my_feed"})
+	assert.NoError(t, err)
+	tg.Templates = templates
+	assert.NoError(t, tg.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident}))
+	assert.Equal(t, telegramMessage{ChatID: "-100", Text: "deviation on my_feed"}, messages[2])
+}
+
+func TestTelegram_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	server.Close() // requests fail with the URL in the error
+
+	tg := NewTelegram("123:secret", "-100")
+	tg.BaseURL = server.URL
+
+	incident := monitor.NewIncidents(nil, nil, nil, []*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}})[0]
+	err := tg.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident})
+	assert.ErrorContains(t, err, "/bot<token>/sendMessage")
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+func TestTelegram_StatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	tg := NewTelegram("123:secret", "-100")
+	tg.BaseURL = server.URL
+
+	incident := monitor.NewIncidents(nil, nil, nil, []*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}})[0]
+	err := tg.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident})
+	assert.EqualError(t, err, "telegram: unexpected status code: 401")
+
+	// The redacted error keeps the status available.
+	var statusErr *monitor.StatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusUnauthorized, statusErr.StatusCode)
+}
@@ -0,0 +1,184 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/errors"
+	"github.com/deividaspetraitis/price-monitor/log"
+)
+
+// Templates are user defined message templates, executed with the monitor.Event being notified.
+//
+// A template is named after an event type (firing, reminder, resolved), an incident kind (deviation, stale,
+// no_consensus, provider_down) or both as kind.type, e.g. provider_down.resolved. The template of an event is
+// looked up as kind.type, kind and type, in this order, notifiers fall back to their built-in message if none
+// is defined.
+type Templates struct {
+	Logger log.Logger // Logger logs the first runtime failure of each template, the default logger unless changed
+
+	templates map[string]*template.Template
+	mu        sync.Mutex
+	failed    map[string]bool // failed holds the names of templates whose failure was logged
+}
+
+// TemplateFuncs are the helper functions available to templates.
+var TemplateFuncs = template.FuncMap{
+	// price formats a price with up to 8 significant digits.
+	"price": func(f float64) string { return fmt.Sprintf("%.8g", f) },
+	// percent formats a fraction as a percentage.
+	"percent": func(f float64) string { return fmt.Sprintf("%.2f%%", f*100) },
+	// duration formats a duration rounded to seconds.
+	"duration": func(d time.Duration) string { return d.Round(time.Second).String() },
+	// time formats a time in UTC.
+	"time": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04:05 MST") },
+	// upper and lower change the case of a value, e.g. of an event type.
+	"upper": func(v any) string { return strings.ToUpper(fmt.Sprint(v)) },
+	"lower": func(v any) string { return strings.ToLower(fmt.Sprint(v)) },
+	"join":  strings.Join,
+}
+
+// templateEventTypes and templateKinds are the event types and incident kinds templates can be named after.
+var (
+	templateEventTypes = []monitor.EventType{monitor.Firing, monitor.Reminder, monitor.Resolved}
+	templateKinds      = []monitor.IncidentKind{monitor.DeviationIncident, monitor.StaleIncident, monitor.NoConsensusIncident, monitor.ProviderDownIncident}
+)
+
+// NewTemplates parses the templates keyed by name and validates them by executing them with sample events.
+func NewTemplates(sources map[string]string) (*Templates, error) {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	t := &Templates{
+		templates: make(map[string]*template.Template, len(sources)),
+		Logger:    log.Default(),
+		failed:    make(map[string]bool),
+	}
+	for _, name := range names {
+		if !validTemplateName(name) {
+			return nil, errors.Newf("template %q: unknown event type or incident kind", name)
+		}
+		tmpl, err := template.New(name).Funcs(TemplateFuncs).Option("missingkey=error").Parse(sources[name])
+		if err != nil {
+			return nil, errors.Wrapf(err, "template %q", name)
+		}
+		t.templates[name] = tmpl
+	}
+
+	for _, event := range sampleEvents() {
+		if _, _, err := t.Render(event); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// Render executes the template of event and reports whether one is defined.
+// It is safe to call on nil Templates.
+func (t *Templates) Render(event monitor.Event) (string, bool, error) {
+	if t == nil {
+		return "", false, nil
+	}
+
+	name, tmpl, ok := t.lookup(event)
+	if !ok {
+		return "", false, nil
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", true, errors.Wrapf(err, "template %q", name)
+	}
+	return buf.String(), true, nil
+}
+
+// lookup returns the template of event and its name, if one is defined.
+func (t *Templates) lookup(event monitor.Event) (string, *template.Template, bool) {
+	kind, typ := string(event.Incident.Kind), string(event.Type)
+	for _, name := range []string{kind + "." + typ, kind, typ} {
+		if tmpl, ok := t.templates[name]; ok {
+			return name, tmpl, true
+		}
+	}
+	return "", nil, false
+}
+
+// execute returns the message of event rendered from t and reports whether a template rendered it.
+// A template failing at runtime, e.g. on a nil field, is logged the first time it fails and reported as not rendered.
+// It is safe to call on nil Templates.
+func (t *Templates) execute(event monitor.Event) (string, bool) {
+	s, ok, err := t.Render(event)
+	if !ok {
+		return "", false
+	}
+	if err != nil {
+		name, _, _ := t.lookup(event)
+
+		t.mu.Lock()
+		first := !t.failed[name]
+		t.failed[name] = true
+		t.mu.Unlock()
+
+		if first && t.Logger != nil {
+			t.Logger.WithError(err).Errorf("template %q failed, falling back to the built-in message", name)
+		}
+		return "", false
+	}
+	return s, true
+}
+
+// render returns the message of event rendered from t, or def if no template is defined or it fails.
+func (t *Templates) render(event monitor.Event, def func() string) string {
+	if s, ok := t.execute(event); ok {
+		return s
+	}
+	return def()
+}
+
+// validTemplateName reports whether name is an event type, an incident kind or kind.type.
+func validTemplateName(name string) bool {
+	if kind, typ, found := strings.Cut(name, "."); found {
+		return slices.Contains(templateKinds, monitor.IncidentKind(kind)) && slices.Contains(templateEventTypes, monitor.EventType(typ))
+	}
+	return slices.Contains(templateKinds, monitor.IncidentKind(name)) || slices.Contains(templateEventTypes, monitor.EventType(name))
+}
+
+// sampleEvents returns an event of every type for an incident of every kind.
+func sampleEvents() []monitor.Event {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	pair := monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}
+	rule := monitor.Rule{Threshold: 0.01, Mode: monitor.Absolute, MaxAge: 5 * time.Minute, Quorum: 3, Enabled: true}
+
+	incidents := monitor.NewIncidents(
+		[]monitor.PriceDifference{{Pair: pair, ServiceA: "SQS", ServiceB: "CoinGecko", PriceA: 0.52, PriceB: 0.5, ObservedAtA: start, ObservedAtB: start, Difference: 0.02, Relative: 0.04, Direction: monitor.Over, Rule: rule}},
+		[]monitor.StalePrice{{PriceData: monitor.PriceData{Pair: pair, Service: "SQS", Price: 0.5, ObservedAt: start, UpdatedAt: start.Add(-10 * time.Minute)}, Age: 10 * time.Minute, Rule: rule}},
+		[]monitor.NoConsensus{{Pair: pair, Providers: []string{"SQS"}, Quorum: 3, Rule: rule}},
+		[]*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}},
+	)
+
+	var events []monitor.Event
+	for _, i := range incidents {
+		i.ID = "0123456789abcdef"
+		i.StartedAt, i.UpdatedAt = start, start.Add(time.Hour)
+		for _, typ := range templateEventTypes {
+			event := monitor.Event{Type: typ, Incident: i, Time: start.Add(time.Hour)}
+			if typ == monitor.Resolved {
+				event.Incident.ResolvedAt = event.Time
+			}
+			events = append(events, event)
+		}
+	}
+	return events
+}
@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTemplates(t *testing.T) {
+	tests := []struct {
+		name          string
+		templates     map[string]string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			templates: map[string]string{
+				"firing":                 `{{upper .Type}} {{.Incident.Summary}}`,
+				"resolved":               `resolved after {{duration (.Incident.ResolvedAt.Sub .Incident.StartedAt)}}`,
+				"deviation":              `{{with .Incident.Difference}}{{.Pair}} {{price .PriceA}} {{percent .Relative}}{{end}}`,
+				"provider_down.resolved": `{{join .Incident.Providers ", "}} recovered at {{time .Time}}`,
+			},
+		},
+		{
+			name:          "unknown name",
+			templates:     map[string]string{"provider-down": `down`},
+			expectedError: `template "provider-down": unknown event type or incident kind`,
+		},
+		{
+			name:          "unknown kind of type",
+			templates:     map[string]string{"stale.paged": `stale`},
+			expectedError: `template "stale.paged": unknown event type or incident kind`,
+		},
+		{
+			name:          "syntax error",
+			templates:     map[string]string{"firing": `{{.Type`},
+			expectedError: `template "firing": template: firing:1: unclosed action`,
+		},
+		{
+			name:          "unknown function",
+			templates:     map[string]string{"firing": `{{money .Type}}`},
+			expectedError: `function "money" not defined`,
+		},
+		{
+			name:          "fails for some kinds",
+			templates:     map[string]string{"firing": `{{.Incident.Difference.PriceA}}`},
+			expectedError: `template "firing": template: firing:1:11: executing "firing" at <.Incident.Difference.PriceA>: nil pointer evaluating *monitor.PriceDifference.PriceA`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTemplates(tt.templates)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTemplates_Render(t *testing.T) {
+	templates, err := NewTemplates(map[string]string{
+		"firing":                 `firing {{.Incident.Kind}}`,
+		"provider_down":          `provider down {{.Type}}`,
+		"provider_down.resolved": `provider up`,
+	})
+	assert.NoError(t, err)
+
+	render := func(typ monitor.EventType, kind monitor.IncidentKind) string {
+		s, ok, err := templates.Render(monitor.Event{Type: typ, Incident: monitor.Incident{Kind: kind}})
+		assert.NoError(t, err)
+		if !ok {
+			return "<default>"
+		}
+		return s
+	}
+
+	assert.Equal(t, "provider up", render(monitor.Resolved, monitor.ProviderDownIncident))
+	assert.Equal(t, "provider down reminder", render(monitor.Reminder, monitor.ProviderDownIncident))
+	assert.Equal(t, "firing stale", render(monitor.Firing, monitor.StaleIncident))
+	assert.Equal(t, "<default>", render(monitor.Resolved, monitor.StaleIncident))
+
+	var none *Templates
+	_, ok, err := none.Render(monitor.Event{Type: monitor.Firing})
+	assert.False(t, ok)
+	assert.NoError(t, err)
+}
+
+func TestTemplates_RenderError(t *testing.T) {
+	templates, err := NewTemplates(map[string]string{"stale": `{{.Incident.Stale.Service}} is stale`})
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	templates.Logger = logrus.NewEntry(logger)
+
+	// The incident lacks its stale price, the template fails at runtime.
+	event := monitor.Event{Type: monitor.Firing, Incident: monitor.Incident{Kind: monitor.StaleIncident}}
+	for range 2 {
+		assert.Equal(t, "default", templates.render(event, func() string { return "default" }))
+	}
+	assert.Equal(t, 1, strings.Count(buf.String(), `template \"stale\" failed`))
+	assert.Contains(t, buf.String(), "nil pointer")
+}
+
+func TestDiscord_Templates(t *testing.T) {
+	var content string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m discordMessage
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&m))
+		content = m.Content
+	}))
+	defer server.Close()
+
+	templates, err := NewTemplates(map[string]string{"stale": `{{.Incident.Stale.Service}} is {{duration .Incident.Stale.Age}} behind`})
+	assert.NoError(t, err)
+
+	d := NewDiscord(server.URL)
+	d.Templates = templates
+
+	stale := monitor.StalePrice{PriceData: monitor.PriceData{Service: "SQS"}, Age: 90 * time.Second}
+	incident := monitor.NewIncidents(nil, []monitor.StalePrice{stale}, nil, nil)[0]
+	assert.NoError(t, d.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident}))
+	assert.Equal(t, "SQS is 1m30s behind", content)
+}
@@ -0,0 +1,268 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/errors"
+)
+
+// WebhookVersion is the version of the webhook payload, it changes whenever the payload changes incompatibly.
+const WebhookVersion = 1
+
+// List of headers of webhook requests.
+const (
+	SignatureHeader = "X-Price-Monitor-Signature" // SignatureHeader holds "sha256=" followed by the hex encoded HMAC-SHA256 of the body
+	EventHeader     = "X-Price-Monitor-Event"     // EventHeader holds the event type
+)
+
+// Webhook is a monitor.Notifier posting alert events as signed JSON payloads to an HTTP endpoint.
+// Deliveries failing with a 5xx status or a transport error are retried with exponential backoff,
+// once retries are exhausted, or the next retry would exceed the retry timeout or the deadline of
+// the context, the event is written to the dead letter log.
+type Webhook struct {
+	URL          string
+	Secret       string        // Secret is the HMAC-SHA256 key signing payloads, payloads are not signed if empty
+	Pairs        monitor.Pairs // Pairs, if set, limits notifications to incidents of these pairs and incidents without a pair
+	Retries      int           // Retries is the number of retries of a failed delivery
+	Backoff      time.Duration // Backoff is the delay before the first retry, doubled for each following retry
+	RetryTimeout time.Duration // RetryTimeout caps the time of a delivery including retries, zero means no cap
+	DeadLetter   io.Writer     // DeadLetter, if set, receives undelivered events as JSON lines
+	HTTPClient   *http.Client
+
+	mu sync.Mutex // serialises writes to DeadLetter
+}
+
+// NewWebhook creates a new Webhook notifier posting to url payloads signed with secret.
+func NewWebhook(url, secret string) *Webhook {
+	return &Webhook{
+		URL:          url,
+		Secret:       secret,
+		Retries:      3,
+		Backoff:      time.Second,
+		RetryTimeout: 30 * time.Second,
+		HTTPClient:   &http.Client{},
+	}
+}
+
+// webhookPayload is the payload posted to webhooks.
+type webhookPayload struct {
+	Version     int                 `json:"version"`
+	Event       monitor.EventType   `json:"event"`
+	Time        time.Time           `json:"time"`
+	Incident    webhookIncident     `json:"incident"`
+	Difference  *webhookDifference  `json:"difference,omitempty"`
+	Stale       *webhookStale       `json:"stale,omitempty"`
+	NoConsensus *webhookNoConsensus `json:"no_consensus,omitempty"`
+	Failure     *webhookFailure     `json:"failure,omitempty"`
+}
+
+type webhookIncident struct {
+	ID         string               `json:"id"`
+	Kind       monitor.IncidentKind `json:"kind"`
+	Summary    string               `json:"summary"`
+	Pair       string               `json:"pair,omitempty"`
+	Base       monitor.Coin         `json:"base,omitempty"`
+	Quote      monitor.Coin         `json:"quote,omitempty"`
+	Providers  []string             `json:"providers"`
+	StartedAt  time.Time            `json:"started_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+	ResolvedAt *time.Time           `json:"resolved_at,omitempty"`
+}
+
+type webhookDifference struct {
+	ServiceA      string                `json:"service_a"`
+	ServiceB      string                `json:"service_b"`
+	PriceA        monitor.Number        `json:"price_a"`
+	PriceB        monitor.Number        `json:"price_b"`
+	ObservedAtA   time.Time             `json:"observed_at_a"`
+	ObservedAtB   time.Time             `json:"observed_at_b"`
+	Difference    monitor.Number        `json:"difference"`
+	Relative      monitor.Number        `json:"relative"`
+	Direction     monitor.Direction     `json:"direction"`
+	Threshold     float64               `json:"threshold"`
+	ThresholdMode monitor.ThresholdMode `json:"threshold_mode"`
+	Strategy      monitor.Strategy      `json:"strategy"`
+}
+
+type webhookStale struct {
+	Service   string         `json:"service"`
+	Price     monitor.Number `json:"price"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Age       string         `json:"age"`
+	MaxAge    string         `json:"max_age"`
+}
+
+type webhookNoConsensus struct {
+	Providers []string `json:"providers"`
+	Quorum    int      `json:"quorum"`
+}
+
+type webhookFailure struct {
+	Provider string `json:"provider"`
+	Error    string `json:"error"`
+}
+
+// newWebhookPayload creates the webhook payload of event.
+func newWebhookPayload(event monitor.Event) webhookPayload {
+	i := event.Incident
+	p := webhookPayload{
+		Version: WebhookVersion,
+		Event:   event.Type,
+		Time:    event.Time,
+		Incident: webhookIncident{
+			ID:        i.ID,
+			Kind:      i.Kind,
+			Summary:   i.Summary(),
+			Providers: i.Providers,
+			StartedAt: i.StartedAt,
+			UpdatedAt: i.UpdatedAt,
+		},
+	}
+	if i.Pair != (monitor.Pair{}) {
+		p.Incident.Pair, p.Incident.Base, p.Incident.Quote = i.Pair.String(), i.Pair.Base, i.Pair.Quote
+	}
+	if !i.ResolvedAt.IsZero() {
+		p.Incident.ResolvedAt = &i.ResolvedAt
+	}
+
+	if d := i.Difference; d != nil {
+		p.Difference = &webhookDifference{
+			ServiceA:      d.ServiceA,
+			ServiceB:      d.ServiceB,
+			PriceA:        monitor.Number(d.PriceA),
+			PriceB:        monitor.Number(d.PriceB),
+			ObservedAtA:   d.ObservedAtA,
+			ObservedAtB:   d.ObservedAtB,
+			Difference:    monitor.Number(d.Difference),
+			Relative:      monitor.Number(d.Relative),
+			Direction:     d.Direction,
+			Threshold:     d.Rule.Threshold,
+			ThresholdMode: d.Rule.Mode,
+			Strategy:      d.Rule.Strategy,
+		}
+	}
+	if s := i.Stale; s != nil {
+		p.Stale = &webhookStale{
+			Service:   s.Service,
+			Price:     monitor.Number(s.Price),
+			UpdatedAt: s.UpdatedAt,
+			Age:       s.Age.String(),
+			MaxAge:    s.Rule.MaxAge.String(),
+		}
+	}
+	if n := i.NoConsensus; n != nil {
+		p.NoConsensus = &webhookNoConsensus{Providers: n.Providers, Quorum: n.Quorum}
+	}
+	if f := i.Failure; f != nil {
+		p.Failure = &webhookFailure{Provider: f.Provider, Error: f.Err.Error()}
+	}
+
+	return p
+}
+
+// Sign returns the value of SignatureHeader of body signed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Notify implements monitor.Notifier.
+func (w *Webhook) Notify(ctx context.Context, event monitor.Event) error {
+	if !w.matches(event.Incident) {
+		return nil
+	}
+
+	body, err := json.Marshal(newWebhookPayload(event))
+	if err != nil {
+		return fmt.Errorf("webhook: failed to encode payload: %w", err)
+	}
+
+	header := make(http.Header)
+	header.Set(EventHeader, string(event.Type))
+	if w.Secret != "" {
+		header.Set(SignatureHeader, Sign(w.Secret, body))
+	}
+
+	if w.RetryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.RetryTimeout)
+		defer cancel()
+	}
+
+	attempts, err := w.deliver(ctx, body, header)
+	if err != nil {
+		w.deadLetter(event, body, attempts, err)
+		return fmt.Errorf("webhook: %w", err)
+	}
+
+	return nil
+}
+
+// deliver posts body to the webhook, retrying deliveries which may succeed later as long as
+// the retry fits in the deadline of ctx. It returns the number of attempts made.
+func (w *Webhook) deliver(ctx context.Context, body []byte, header http.Header) (int, error) {
+	backoff := w.Backoff
+	for attempt := 1; ; attempt++ {
+		err := post(ctx, w.HTTPClient, w.URL, body, header)
+		if err == nil || !retryable(err) || attempt > w.Retries {
+			return attempt, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			return attempt, err // give up now rather than when the deadline passed
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return attempt, errors.Wrap(ctx.Err(), err.Error())
+		}
+	}
+}
+
+// retryable reports whether a delivery which failed with err may succeed later.
+func retryable(err error) bool {
+	var statusErr *monitor.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	return true
+}
+
+// matches reports whether the webhook is notified of the incident.
+func (w *Webhook) matches(i monitor.Incident) bool {
+	return len(w.Pairs) == 0 || i.Pair == (monitor.Pair{}) || slices.Contains(w.Pairs, i.Pair)
+}
+
+// deadLetter writes an undelivered event to the dead letter log.
+func (w *Webhook) deadLetter(event monitor.Event, body []byte, attempts int, err error) {
+	if w.DeadLetter == nil {
+		return
+	}
+
+	line, _ := json.Marshal(struct {
+		Time     time.Time         `json:"time"`
+		URL      string            `json:"url"`
+		Event    monitor.EventType `json:"event"`
+		Incident string            `json:"incident"`
+		Attempts int               `json:"attempts"`
+		Error    string            `json:"error"`
+		Payload  json.RawMessage   `json:"payload"`
+	}{time.Now().UTC(), w.URL, event.Type, event.Incident.ID, attempts, err.Error(), body})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.DeadLetter.Write(append(line, '\n'))
+}
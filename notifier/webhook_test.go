@@ -0,0 +1,188 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook_Notify(t *testing.T) {
+	start := time.Date(2024, 12, 1, 10, 0, 0, 0, time.UTC)
+	diff := monitor.PriceDifference{
+		Pair:        monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD},
+		ServiceA:    "SQS",
+		ServiceB:    "CoinGecko",
+		PriceA:      0.52,
+		PriceB:      0.5,
+		ObservedAtA: start,
+		ObservedAtB: start,
+		Difference:  0.02,
+		Relative:    0.04,
+		Direction:   monitor.Over,
+		Rule:        monitor.Rule{Threshold: 0.01, Mode: monitor.Absolute, Strategy: monitor.Pairwise},
+	}
+	incident := monitor.NewIncidents([]monitor.PriceDifference{diff}, nil, nil, nil)[0]
+	incident.ID = "abc"
+	incident.StartedAt = start
+	incident.UpdatedAt = start
+
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+	}))
+	defer server.Close()
+
+	w := NewWebhook(server.URL, "secret")
+	assert.NoError(t, w.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident, Time: start}))
+
+	assert.Equal(t, Sign("secret", body), header.Get(SignatureHeader))
+	assert.Equal(t, "firing", header.Get(EventHeader))
+	assert.JSONEq(t, `{
+		"version": 1,
+		"event": "firing",
+		"time": "2024-12-01T10:00:00Z",
+		"incident": {
+			"id": "abc",
+			"kind": "deviation",
+			"summary": "price difference for pair osmo/usd between SQS (0.52) and CoinGecko (0.5) is 0.0200 (4.00%), exceeding absolute threshold 0.01",
+			"pair": "osmo/usd",
+			"base": "osmo",
+			"quote": "usd",
+			"providers": ["SQS", "CoinGecko"],
+			"started_at": "2024-12-01T10:00:00Z",
+			"updated_at": "2024-12-01T10:00:00Z"
+		},
+		"difference": {
+			"service_a": "SQS",
+			"service_b": "CoinGecko",
+			"price_a": 0.52,
+			"price_b": 0.5,
+			"observed_at_a": "2024-12-01T10:00:00Z",
+			"observed_at_b": "2024-12-01T10:00:00Z",
+			"difference": 0.02,
+			"relative": 0.04,
+			"direction": "over",
+			"threshold": 0.01,
+			"threshold_mode": "absolute",
+			"strategy": "pairwise"
+		}
+	}`, string(body))
+}
+
+func TestWebhook_Retry(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []int
+		backoff          time.Duration
+		expectedAttempts int
+		expectedError    string
+	}{
+		{
+			name:             "recovers after server errors",
+			statuses:         []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			expectedAttempts: 3,
+		},
+		{
+			name:             "retries exhausted",
+			statuses:         []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			expectedAttempts: 3,
+			expectedError:    "webhook: unexpected status code: 500",
+		},
+		{
+			name:             "retry exceeding retry timeout is not made",
+			statuses:         []int{http.StatusInternalServerError, http.StatusOK},
+			backoff:          time.Minute,
+			expectedAttempts: 1,
+			expectedError:    "webhook: unexpected status code: 500",
+		},
+		{
+			name:             "client error is not retried",
+			statuses:         []int{http.StatusBadRequest},
+			expectedAttempts: 1,
+			expectedError:    "webhook: unexpected status code: 400",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[attempts])
+				attempts++
+			}))
+			defer server.Close()
+
+			var deadLetter bytes.Buffer
+			w := NewWebhook(server.URL, "secret")
+			w.Retries = 2
+			w.Backoff = time.Millisecond
+			w.DeadLetter = &deadLetter
+			if tt.backoff > 0 {
+				w.Backoff = tt.backoff
+			}
+
+			incident := monitor.NewIncidents(nil, nil, nil, []*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}})[0]
+			incident.ID = "abc"
+			err := w.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: incident})
+
+			assert.Equal(t, tt.expectedAttempts, attempts)
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Empty(t, deadLetter.String())
+				return
+			}
+
+			assert.EqualError(t, err, tt.expectedError)
+
+			var line struct {
+				URL      string         `json:"url"`
+				Incident string         `json:"incident"`
+				Attempts int            `json:"attempts"`
+				Error    string         `json:"error"`
+				Payload  webhookPayload `json:"payload"`
+			}
+			assert.NoError(t, json.Unmarshal(deadLetter.Bytes(), &line))
+			assert.Equal(t, server.URL, line.URL)
+			assert.Equal(t, "abc", line.Incident)
+			assert.Equal(t, tt.expectedAttempts, line.Attempts)
+			assert.Equal(t, "SQS", line.Payload.Failure.Provider)
+		})
+	}
+}
+
+func TestWebhook_Pairs(t *testing.T) {
+	var events []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhookPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		events = append(events, p.Incident.Pair+" "+string(p.Incident.Kind))
+	}))
+	defer server.Close()
+
+	w := NewWebhook(server.URL, "")
+	w.Pairs = monitor.Pairs{{Base: "atom", Quote: monitor.USD}}
+
+	incidents := monitor.NewIncidents(nil,
+		[]monitor.StalePrice{
+			{PriceData: monitor.PriceData{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "SQS"}},
+			{PriceData: monitor.PriceData{Pair: monitor.Pair{Base: "atom", Quote: monitor.USD}, Service: "SQS"}},
+		},
+		nil,
+		[]*monitor.ProviderError{{Provider: "SQS", Err: context.DeadlineExceeded}},
+	)
+	for _, i := range incidents {
+		assert.NoError(t, w.Notify(context.Background(), monitor.Event{Type: monitor.Firing, Incident: i}))
+	}
+
+	assert.Equal(t, []string{"atom/usd stale", " provider_down"}, events)
+}
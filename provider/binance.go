@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+)
+
+// Binance is the name of the Binance provider, also used to look up coin identifiers in the registry.
+const Binance = "Binance"
+
+// binanceRetryAfter is the back off after rate limit responses without a valid Retry-After header.
+const binanceRetryAfter = time.Minute
+
+// BinanceClient represents the client to interact with the Binance spot API.
+type BinanceClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Registry   *monitor.Registry
+	Symbols    map[monitor.Pair]string // Symbols maps pairs to Binance symbols, pairs without one are the concatenation of coin identifiers, e.g. OSMOUSDT
+
+	mu      sync.Mutex
+	backoff *BinanceError // backoff is the last rate limit response, no requests are made until its RetryAfter passed
+	until   time.Time     // until is the end of the back off
+}
+
+// NewBinanceClient creates a new instance of the BinanceClient.
+func NewBinanceClient(registry *monitor.Registry) *BinanceClient {
+	return &BinanceClient{
+		BaseURL:    "https://api.binance.com",
+		HTTPClient: &http.Client{},
+		Registry:   registry,
+	}
+}
+
+// Name returns the name of the provider.
+func (c *BinanceClient) Name() string {
+	return Binance
+}
+
+// BinanceError is an error returned by the Binance API.
+type BinanceError struct {
+	StatusCode int
+	Code       int           `json:"code"`
+	Msg        string        `json:"msg"`
+	RetryAfter time.Duration // RetryAfter is the back off requested by rate limit responses
+}
+
+// Error implements error.
+func (e *BinanceError) Error() string {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests:
+		return fmt.Sprintf("binance: request weight limit exceeded, retry after %s", e.RetryAfter)
+	case http.StatusTeapot:
+		return fmt.Sprintf("binance: IP banned for exceeding request weight limit, retry after %s", e.RetryAfter)
+	}
+	return fmt.Sprintf("binance: %s (code %d, status %d)", e.Msg, e.Code, e.StatusCode)
+}
+
+// Unwrap returns the StatusError of the response.
+func (e *BinanceError) Unwrap() error {
+	return &monitor.StatusError{StatusCode: e.StatusCode}
+}
+
+// Resolve returns why the pair can't be priced, nil if it can. It implements monitor.PairResolver.
+func (c *BinanceClient) Resolve(pair monitor.Pair) error {
+	if _, ok := c.Symbols[pair]; ok {
+		return nil
+	}
+	return resolveCoinIDs(c.Registry, pair, Binance)
+}
+
+// GetPrices fetches the latest prices of the pairs from the Binance symbol price ticker.
+// After a rate limit or ban response it fails with the same BinanceError, without requesting the API,
+// until the requested back off passed, as ignoring rate limits escalates them to IP bans.
+func (c *BinanceClient) GetPrices(ctx context.Context, cryptos monitor.Pairs) ([]monitor.PriceData, error) {
+	if err := c.backingOff(time.Now()); err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, len(cryptos))
+	for i, pair := range cryptos {
+		var err error
+		if symbols[i], err = c.symbol(pair); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(nonEmpty(symbols)) == 0 {
+		return nil, nil
+	}
+
+	query, err := json.Marshal(unique(nonEmpty(symbols)))
+	if err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/api/v3/ticker/price?symbols=%s", c.BaseURL, url.QueryEscape(string(query)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch prices: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &BinanceError{StatusCode: resp.StatusCode}
+		json.NewDecoder(resp.Body).Decode(apiErr) // the body is an error JSON, if any
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		if apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode == http.StatusTeapot {
+			if apiErr.RetryAfter <= 0 {
+				apiErr.RetryAfter = binanceRetryAfter
+			}
+			c.backOff(apiErr, time.Now())
+		}
+		return nil, apiErr
+	}
+
+	var tickers []struct {
+		Symbol string `json:"symbol"`
+		Price  string `json:"price"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tickers); err != nil {
+		return nil, err
+	}
+
+	rawPrices := make(map[string]string, len(tickers))
+	for _, t := range tickers {
+		rawPrices[t.Symbol] = t.Price
+	}
+
+	var pricesData []monitor.PriceData
+	for i, pair := range cryptos {
+		if priceStr, ok := rawPrices[symbols[i]]; ok {
+			price, err := strconv.ParseFloat(priceStr, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse price: %w", err)
+			}
+			pricesData = append(pricesData, monitor.PriceData{
+				Pair:    pair,
+				Service: Binance,
+				Price:   price,
+			})
+		}
+	}
+
+	return pricesData, nil
+}
+
+// backOff stops requests until the back off requested by the rate limit response err passed.
+func (c *BinanceClient) backOff(err *BinanceError, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.backoff, c.until = err, now.Add(err.RetryAfter)
+}
+
+// backingOff returns the rate limit error, with the remaining back off, if requests are not allowed at now.
+func (c *BinanceClient) backingOff(now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.backoff == nil || !now.Before(c.until) {
+		return nil
+	}
+	err := *c.backoff
+	err.RetryAfter = c.until.Sub(now).Round(time.Second)
+	return &err
+}
+
+// symbol returns the Binance symbol of the pair, or an empty string if its coins have no identifiers for Binance.
+func (c *BinanceClient) symbol(pair monitor.Pair) (string, error) {
+	if s, ok := c.Symbols[pair]; ok {
+		return s, nil
+	}
+
+	base, quote, ok, err := coinIDs(c.Registry, pair, Binance)
+	if err != nil || !ok {
+		return "", err
+	}
+	return strings.ToUpper(base + quote), nil
+}
@@ -0,0 +1,222 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinanceClient_GetPrices(t *testing.T) {
+	registry := monitor.DefaultRegistry()
+	assert.NoError(t, registry.Register(monitor.CoinInfo{Symbol: "atom", IDs: map[string]string{"coingecko": "cosmos"}}))
+
+	tests := []struct {
+		name           string
+		pairs          monitor.Pairs
+		symbols        map[monitor.Pair]string
+		status         int
+		header         map[string]string
+		mockResponse   string
+		expectedQuery  string
+		expectedPrices []monitor.PriceData
+		expectedError  string
+		expectedStatus int
+		cancelContext  bool
+	}{
+		{
+			name:          "successful request",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			mockResponse:  `[{"symbol":"OSMOUSDT","price":"0.52310000"}]`,
+			expectedQuery: `["OSMOUSDT"]`,
+			expectedPrices: []monitor.PriceData{
+				{
+					Pair:    monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD},
+					Service: "Binance",
+					Price:   0.5231,
+				},
+			},
+		},
+		{
+			name:          "symbol mapping",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}, {Base: "atom", Quote: monitor.USD}},
+			symbols:       map[monitor.Pair]string{{Base: "atom", Quote: monitor.USD}: "ATOMFDUSD"},
+			mockResponse:  `[{"symbol":"OSMOUSDT","price":"0.5"},{"symbol":"ATOMFDUSD","price":"6.1"}]`,
+			expectedQuery: `["OSMOUSDT","ATOMFDUSD"]`,
+			expectedPrices: []monitor.PriceData{
+				{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "Binance", Price: 0.5},
+				{Pair: monitor.Pair{Base: "atom", Quote: monitor.USD}, Service: "Binance", Price: 6.1},
+			},
+		},
+		{
+			name:          "missing price",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			mockResponse:  `[]`,
+			expectedQuery: `["OSMOUSDT"]`,
+		},
+		{
+			name:           "error json",
+			pairs:          monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			status:         http.StatusBadRequest,
+			mockResponse:   `{"code":-1121,"msg":"Invalid symbol."}`,
+			expectedError:  "binance: Invalid symbol. (code -1121, status 400)",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "rate limited",
+			pairs:          monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			status:         http.StatusTooManyRequests,
+			header:         map[string]string{"Retry-After": "30"},
+			mockResponse:   `{"code":-1003,"msg":"Too much request weight used."}`,
+			expectedError:  "binance: request weight limit exceeded, retry after 30s",
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:           "banned",
+			pairs:          monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			status:         http.StatusTeapot,
+			header:         map[string]string{"Retry-After": "120"},
+			expectedError:  "binance: IP banned for exceeding request weight limit, retry after 2m0s",
+			expectedStatus: http.StatusTeapot,
+		},
+		{
+			name:          "coin without identifier is skipped",
+			pairs:         monitor.Pairs{{Base: "atom", Quote: monitor.USD}, {Base: monitor.OSMO, Quote: monitor.USD}},
+			mockResponse:  `[{"symbol":"OSMOUSDT","price":"0.5"}]`,
+			expectedQuery: `["OSMOUSDT"]`,
+			expectedPrices: []monitor.PriceData{
+				{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "Binance", Price: 0.5},
+			},
+		},
+		{
+			name:          "unknown coin",
+			pairs:         monitor.Pairs{{Base: "doge", Quote: monitor.USD}},
+			expectedError: "unknown coin",
+		},
+		{
+			name:          "context cancelled",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			mockResponse:  `[]`,
+			expectedError: "context canceled",
+			cancelContext: true,
+		},
+		{
+			name:          "invalid price format",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			mockResponse:  `[{"symbol":"OSMOUSDT","price":"invalid"}]`,
+			expectedError: "failed to parse price",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v3/ticker/price", r.URL.Path)
+				if tt.expectedQuery != "" {
+					assert.Equal(t, tt.expectedQuery, r.URL.Query().Get("symbols"))
+				}
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			client := NewBinanceClient(registry)
+			client.BaseURL = server.URL
+			client.HTTPClient = server.Client()
+			client.Symbols = tt.symbols
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if tt.cancelContext {
+				cancel()
+			}
+
+			prices, err := client.GetPrices(ctx, tt.pairs)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				if tt.expectedStatus != 0 {
+					pe := &monitor.ProviderError{Provider: Binance, Err: err}
+					assert.Equal(t, tt.expectedStatus, pe.StatusCode())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPrices, prices)
+			}
+		})
+	}
+}
+
+func TestBinanceClient_BackOff(t *testing.T) {
+	var requests int
+	limited := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if limited {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"code":-1003,"msg":"Too much request weight used."}`))
+			return
+		}
+		w.Write([]byte(`[{"symbol":"OSMOUSDT","price":"0.5"}]`))
+	}))
+	defer server.Close()
+
+	client := NewBinanceClient(monitor.DefaultRegistry())
+	client.BaseURL = server.URL
+	client.HTTPClient = server.Client()
+
+	pairs := monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}}
+
+	_, err := client.GetPrices(context.Background(), pairs)
+	assert.EqualError(t, err, "binance: request weight limit exceeded, retry after 30s")
+	assert.Equal(t, 1, requests)
+
+	// The API is not requested again while backing off.
+	_, err = client.GetPrices(context.Background(), pairs)
+	assert.EqualError(t, err, "binance: request weight limit exceeded, retry after 30s")
+	pe := &monitor.ProviderError{Provider: Binance, Err: err}
+	assert.Equal(t, http.StatusTooManyRequests, pe.StatusCode())
+	assert.Equal(t, 1, requests)
+
+	// Requests resume once the back off passed.
+	limited = false
+	client.until = time.Now().Add(-time.Second)
+	prices, err := client.GetPrices(context.Background(), pairs)
+	assert.NoError(t, err)
+	assert.Len(t, prices, 1)
+	assert.Equal(t, 2, requests)
+}
+
+func TestBinanceClient_BackOffWithoutRetryAfter(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	client := NewBinanceClient(monitor.DefaultRegistry())
+	client.BaseURL = server.URL
+	client.HTTPClient = server.Client()
+
+	pairs := monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}}
+
+	// Without Retry-After the client backs off for the default duration.
+	_, err := client.GetPrices(context.Background(), pairs)
+	assert.EqualError(t, err, "binance: IP banned for exceeding request weight limit, retry after 1m0s")
+	_, err = client.GetPrices(context.Background(), pairs)
+	assert.Error(t, err)
+	assert.Equal(t, 1, requests)
+}
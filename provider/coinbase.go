@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+)
+
+// Coinbase is the name of the Coinbase provider, also used to look up coin identifiers in the registry.
+const Coinbase = "Coinbase"
+
+// CoinbaseClient represents the client to interact with the Coinbase Exchange API.
+type CoinbaseClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Registry   *monitor.Registry
+	Products   map[monitor.Pair]string // Products maps pairs to product ids, pairs without one are coin identifiers joined by a dash, e.g. OSMO-USD
+}
+
+// NewCoinbaseClient creates a new instance of the CoinbaseClient.
+func NewCoinbaseClient(registry *monitor.Registry) *CoinbaseClient {
+	return &CoinbaseClient{
+		BaseURL:    "https://api.exchange.coinbase.com",
+		HTTPClient: &http.Client{},
+		Registry:   registry,
+	}
+}
+
+// Name returns the name of the provider.
+func (c *CoinbaseClient) Name() string {
+	return Coinbase
+}
+
+// Resolve returns why the pair can't be priced, nil if it can. It implements monitor.PairResolver.
+func (c *CoinbaseClient) Resolve(pair monitor.Pair) error {
+	if _, ok := c.Products[pair]; ok {
+		return nil
+	}
+	return resolveCoinIDs(c.Registry, pair, Coinbase)
+}
+
+// GetPrices fetches the last trade prices of the pairs from the product tickers, one request per product.
+// Pairs of unknown products, or whose coins have no Coinbase identifiers, are left out.
+func (c *CoinbaseClient) GetPrices(ctx context.Context, cryptos monitor.Pairs) ([]monitor.PriceData, error) {
+	products := make([]string, len(cryptos))
+	for i, pair := range cryptos {
+		var err error
+		if products[i], err = c.product(pair); err != nil {
+			return nil, err
+		}
+	}
+
+	var pricesData []monitor.PriceData
+	for i, pair := range cryptos {
+		if products[i] == "" {
+			continue
+		}
+		price, updatedAt, ok, err := c.ticker(ctx, products[i])
+		if err != nil {
+			return nil, fmt.Errorf("product %s: %w", products[i], err)
+		}
+		if !ok {
+			continue
+		}
+		pricesData = append(pricesData, monitor.PriceData{
+			Pair:      pair,
+			Service:   Coinbase,
+			Price:     price,
+			UpdatedAt: updatedAt,
+		})
+	}
+
+	return pricesData, nil
+}
+
+// ticker fetches the last trade price of the product and reports whether the product exists.
+func (c *CoinbaseClient) ticker(ctx context.Context, product string) (float64, time.Time, bool, error) {
+	url := fmt.Sprintf("%s/products/%s/ticker", c.BaseURL, url.PathEscape(product))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, time.Time{}, false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, time.Time{}, false, fmt.Errorf("failed to fetch prices: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return 0, time.Time{}, false, nil
+	default:
+		return 0, time.Time{}, false, &monitor.StatusError{StatusCode: resp.StatusCode}
+	}
+
+	var ticker struct {
+		Price string    `json:"price"`
+		Time  time.Time `json:"time"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ticker); err != nil {
+		return 0, time.Time{}, false, err
+	}
+
+	price, err := strconv.ParseFloat(ticker.Price, 64)
+	if err != nil {
+		return 0, time.Time{}, false, fmt.Errorf("failed to parse price: %w", err)
+	}
+
+	return price, ticker.Time, true, nil
+}
+
+// product returns the Coinbase product id of the pair, or an empty string if its coins have no identifiers for Coinbase.
+func (c *CoinbaseClient) product(pair monitor.Pair) (string, error) {
+	if id, ok := c.Products[pair]; ok {
+		return id, nil
+	}
+
+	base, quote, ok, err := coinIDs(c.Registry, pair, Coinbase)
+	if err != nil || !ok {
+		return "", err
+	}
+	return strings.ToUpper(base + "-" + quote), nil
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoinbaseClient_GetPrices(t *testing.T) {
+	tests := []struct {
+		name           string
+		pairs          monitor.Pairs
+		products       map[monitor.Pair]string
+		responses      map[string]string // responses are keyed by request path, unknown paths are not found
+		status         int
+		expectedPrices []monitor.PriceData
+		expectedError  string
+		expectedStatus int
+		cancelContext  bool
+	}{
+		{
+			name:      "successful request",
+			pairs:     monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			responses: map[string]string{"/products/OSMO-USD/ticker": `{"trade_id":1,"price":"0.5231","size":"10","time":"2024-05-01T12:00:00.123Z","bid":"0.523","ask":"0.524","volume":"1000"}`},
+			expectedPrices: []monitor.PriceData{
+				{
+					Pair:      monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD},
+					Service:   "Coinbase",
+					Price:     0.5231,
+					UpdatedAt: time.Date(2024, 5, 1, 12, 0, 0, 123000000, time.UTC),
+				},
+			},
+		},
+		{
+			name:     "product mapping",
+			pairs:    monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}, {Base: "atom", Quote: monitor.USD}},
+			products: map[monitor.Pair]string{{Base: "atom", Quote: monitor.USD}: "ATOM-USDC"},
+			responses: map[string]string{
+				"/products/OSMO-USD/ticker":  `{"price":"0.5","time":"2024-05-01T12:00:00Z"}`,
+				"/products/ATOM-USDC/ticker": `{"price":"6.1","time":"2024-05-01T12:00:01Z"}`,
+			},
+			expectedPrices: []monitor.PriceData{
+				{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "Coinbase", Price: 0.5, UpdatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+				{Pair: monitor.Pair{Base: "atom", Quote: monitor.USD}, Service: "Coinbase", Price: 6.1, UpdatedAt: time.Date(2024, 5, 1, 12, 0, 1, 0, time.UTC)},
+			},
+		},
+		{
+			name:  "unknown product",
+			pairs: monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+		},
+		{
+			name:           "error response",
+			pairs:          monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			status:         http.StatusTooManyRequests,
+			expectedError:  "product OSMO-USD: unexpected status code: 429",
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:          "unknown coin",
+			pairs:         monitor.Pairs{{Base: "doge", Quote: monitor.USD}},
+			expectedError: "unknown coin",
+		},
+		{
+			name:          "context cancelled",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			expectedError: "context canceled",
+			cancelContext: true,
+		},
+		{
+			name:          "invalid price format",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			responses:     map[string]string{"/products/OSMO-USD/ticker": `{"price":"invalid"}`},
+			expectedError: "failed to parse price",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				response, ok := tt.responses[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte(`{"message":"NotFound"}`))
+					return
+				}
+				w.Write([]byte(response))
+			}))
+			defer server.Close()
+
+			client := NewCoinbaseClient(monitor.DefaultRegistry())
+			client.BaseURL = server.URL
+			client.HTTPClient = server.Client()
+			client.Products = tt.products
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if tt.cancelContext {
+				cancel()
+			}
+
+			prices, err := client.GetPrices(ctx, tt.pairs)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				if tt.expectedStatus != 0 {
+					pe := &monitor.ProviderError{Provider: Coinbase, Err: err}
+					assert.Equal(t, tt.expectedStatus, pe.StatusCode())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPrices, prices)
+			}
+		})
+	}
+}
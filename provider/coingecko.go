@@ -6,54 +6,55 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/deividaspetraitis/price-monitor"
 )
 
+// CoinGecko is the name of the CoinGecko provider, also used to look up coin identifiers in the registry.
+const CoinGecko = "CoinGecko"
+
 // CoinGeckoClient represents the client to interact with the CoinGecko API.
 type CoinGeckoClient struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	Registry   *monitor.Registry
 }
 
 // NewCoinGeckoClient creates a new instance of the CoinGeckoClient.
-func NewCoinGeckoClient() *CoinGeckoClient {
+func NewCoinGeckoClient(registry *monitor.Registry) *CoinGeckoClient {
 	return &CoinGeckoClient{
 		BaseURL:    "https://api.coingecko.com/api/v3",
 		HTTPClient: &http.Client{},
+		Registry:   registry,
 	}
 }
 
-// CoinGeckoCoin represents a cryptocurrency in the CoinGecko API.
-type CoinGeckoCoin struct {
-	Coin monitor.Coin
-}
-
-// String returns the string representation of the CoinGeckoCoin.
-func (c CoinGeckoCoin) String() string {
-	switch c.Coin {
-	case monitor.OSMO:
-		return "osmosis"
-	case monitor.USD:
-		return "usd"
-	}
-	return ""
+// Name returns the name of the provider.
+func (c *CoinGeckoClient) Name() string {
+	return CoinGecko
 }
 
-// NewCoinGeckoCoin creates a new instance of the CoinGeckoCoin.
-func NewCoinGeckoCoin(coin monitor.Coin) CoinGeckoCoin {
-	return CoinGeckoCoin{Coin: coin}
+// Resolve returns why the pair can't be priced, nil if it can. It implements monitor.PairResolver.
+func (c *CoinGeckoClient) Resolve(pair monitor.Pair) error {
+	return resolveCoinIDs(c.Registry, pair, CoinGecko)
 }
 
 // GetPrices fetches the prices of cryptocurrencies in the specified currency.
 func (c *CoinGeckoClient) GetPrices(ctx context.Context, cryptos monitor.Pairs) ([]monitor.PriceData, error) {
 	baseCoins := make([]string, len(cryptos))
+	quoteCoins := make([]string, len(cryptos))
 	for i, pair := range cryptos {
-		baseCoins[i] = NewCoinGeckoCoin(pair.Base).String()
+		var err error
+		if baseCoins[i], quoteCoins[i], _, err = coinIDs(c.Registry, pair, CoinGecko); err != nil {
+			return nil, err
+		}
+	}
+	if len(nonEmpty(baseCoins)) == 0 {
+		return nil, nil
 	}
 
-	quoteCoin := NewCoinGeckoCoin(cryptos[0].Quote).String()
-	url := fmt.Sprintf("%s/simple/price?ids=%s&vs_currencies=%s", c.BaseURL, strings.Join(baseCoins, ","), quoteCoin)
+	url := fmt.Sprintf("%s/simple/price?include_last_updated_at=true&ids=%s&vs_currencies=%s", c.BaseURL, strings.Join(unique(nonEmpty(baseCoins)), ","), strings.Join(unique(nonEmpty(quoteCoins)), ","))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -67,23 +68,27 @@ func (c *CoinGeckoClient) GetPrices(ctx context.Context, cryptos monitor.Pairs)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &monitor.StatusError{StatusCode: resp.StatusCode}
 	}
 
+	// Prices are keyed by coin and quote currency, next to them "last_updated_at" holds the unix time of the quote.
 	var rawPrices map[string]map[string]float64
 	if err := json.NewDecoder(resp.Body).Decode(&rawPrices); err != nil {
 		return nil, err
 	}
 
 	var pricesData []monitor.PriceData
-	for _, pair := range cryptos {
-		baseCoin := NewCoinGeckoCoin(pair.Base).String()
-		quoteCoin := NewCoinGeckoCoin(pair.Quote).String()
-		if price, ok := rawPrices[baseCoin][quoteCoin]; ok {
+	for i, pair := range cryptos {
+		if price, ok := rawPrices[baseCoins[i]][quoteCoins[i]]; ok {
+			var updatedAt time.Time
+			if ts, ok := rawPrices[baseCoins[i]]["last_updated_at"]; ok {
+				updatedAt = time.Unix(int64(ts), 0)
+			}
 			pricesData = append(pricesData, monitor.PriceData{
-				Pair:    pair,
-				Service: "CoinGecko",
-				Price:   price,
+				Pair:      pair,
+				Service:   CoinGecko,
+				Price:     price,
+				UpdatedAt: updatedAt,
 			})
 		}
 	}
@@ -35,6 +35,21 @@ func TestCoinGeckoClient_GetPrices(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "last updated at",
+			pairs: monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			mockResponse: map[string]map[string]float64{
+				"osmosis": {"usd": 1.23, "last_updated_at": 1733047200},
+			},
+			expectedPrices: []monitor.PriceData{
+				{
+					Pair:      monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD},
+					Service:   "CoinGecko",
+					Price:     1.23,
+					UpdatedAt: time.Unix(1733047200, 0),
+				},
+			},
+		},
 		{
 			name:          "server error",
 			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
@@ -49,6 +64,12 @@ func TestCoinGeckoClient_GetPrices(t *testing.T) {
 			},
 			expectedPrices: nil,
 		},
+		{
+			name:          "unknown coin",
+			pairs:         monitor.Pairs{{Base: "doge", Quote: monitor.USD}},
+			mockResponse:  map[string]map[string]float64{},
+			expectedError: "unknown coin",
+		},
 		{
 			name:          "context cancelled",
 			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
@@ -73,6 +94,7 @@ func TestCoinGeckoClient_GetPrices(t *testing.T) {
 			client := &CoinGeckoClient{
 				BaseURL:    server.URL,
 				HTTPClient: server.Client(),
+				Registry:   monitor.DefaultRegistry(),
 			}
 
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,305 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/errors"
+)
+
+// JSONClient represents a declarative client fetching prices from any JSON HTTP API.
+//
+// URL and Path are templates in which the placeholders {id}, {base} and {quote} are replaced for each pair:
+// {id} is the identifier of the pair from IDs, {base} and {quote} are the identifiers of the coins registered
+// for the provider name, or the coin symbols if none are. Pairs rendering the same URL share a single request.
+// Values are escaped as path segments before the ? of the URL and as query values after it, in Path they
+// are substituted into the keys of the parsed expression, so a value containing dots stays a single key.
+type JSONClient struct {
+	Source     string                  // Source is the provider name
+	URL        string                  // URL is the template of the request URL
+	Path       string                  // Path is the template of the JSONPath-like expression locating the price, e.g. $.data.price or prices[0].value
+	Headers    map[string]string       // Headers are sent with every request
+	Scale      float64                 // Scale multiplies the located price, e.g. 1e-6 for amounts in micro units
+	IDs        map[monitor.Pair]string // IDs map pairs to the identifiers substituted for {id}
+	HTTPClient *http.Client
+	Registry   *monitor.Registry
+}
+
+// NewJSONClient creates a new instance of the JSONClient named source, locating prices at path in responses of url.
+// The path is validated up front so that a broken expression fails at startup rather than on every fetch.
+func NewJSONClient(source, url, path string, registry *monitor.Registry) (*JSONClient, error) {
+	if _, err := parseJSONPath(path); err != nil {
+		return nil, err
+	}
+	return &JSONClient{
+		Source:     source,
+		URL:        url,
+		Path:       path,
+		Scale:      1,
+		HTTPClient: &http.Client{},
+		Registry:   registry,
+	}, nil
+}
+
+// Name returns the name of the provider.
+func (c *JSONClient) Name() string {
+	return c.Source
+}
+
+// Resolve returns why the pair can't be priced, nil if it can. It implements monitor.PairResolver.
+func (c *JSONClient) Resolve(pair monitor.Pair) error {
+	_, ok, err := c.replacer(pair)
+	if err == nil && !ok {
+		err = errors.Newf("no id for pair %s", pair)
+	}
+	return err
+}
+
+// GetPrices fetches the prices of the pairs, requesting each distinct URL once.
+// Pairs whose path does not resolve to a value are left out.
+func (c *JSONClient) GetPrices(ctx context.Context, cryptos monitor.Pairs) ([]monitor.PriceData, error) {
+	path, err := parseJSONPath(c.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	bodies := make(map[string]any)
+
+	var pricesData []monitor.PriceData
+	for _, pair := range cryptos {
+		replacer, ok, err := c.replacer(pair)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		url := c.url(replacer)
+		body, ok := bodies[url]
+		if !ok {
+			if body, err = c.fetch(ctx, url); err != nil {
+				return nil, err
+			}
+			bodies[url] = body
+		}
+
+		value, ok := path.replace(replacer(func(s string) string { return s })).eval(body)
+		if !ok {
+			continue
+		}
+		price, err := jsonFloat(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse price: %w", err)
+		}
+
+		pricesData = append(pricesData, monitor.PriceData{
+			Pair:    pair,
+			Service: c.Source,
+			Price:   price * c.Scale,
+		})
+	}
+
+	return pricesData, nil
+}
+
+// replacer returns a function building the replacer of the placeholders of the pair, with values escaped by escape.
+// It reports false if the pair has no identifiers, i.e. the templates use {id} but the pair is missing from IDs.
+func (c *JSONClient) replacer(pair monitor.Pair) (func(escape func(string) string) *strings.Replacer, bool, error) {
+	id, hasID := c.IDs[pair]
+	if !hasID && (strings.Contains(c.URL, "{id}") || strings.Contains(c.Path, "{id}")) {
+		return nil, false, nil
+	}
+
+	coinID := func(coin monitor.Coin) (string, error) {
+		info, err := c.Registry.Lookup(coin)
+		if err != nil {
+			return "", err
+		}
+		if id, ok := info.IDs[strings.ToLower(c.Source)]; ok && id != "" {
+			return id, nil
+		}
+		return info.Symbol.String(), nil
+	}
+	base, err := coinID(pair.Base)
+	if err != nil {
+		return nil, false, err
+	}
+	quote, err := coinID(pair.Quote)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return func(escape func(string) string) *strings.Replacer {
+		return strings.NewReplacer("{id}", escape(id), "{base}", escape(base), "{quote}", escape(quote))
+	}, true, nil
+}
+
+// url renders the URL template with the placeholders replaced by replacer, escaping the values
+// as path segments before the query and as query values in it.
+func (c *JSONClient) url(replacer func(escape func(string) string) *strings.Replacer) string {
+	path, query, hasQuery := strings.Cut(c.URL, "?")
+	path = replacer(url.PathEscape).Replace(path)
+	if !hasQuery {
+		return path
+	}
+	return path + "?" + replacer(url.QueryEscape).Replace(query)
+}
+
+// fetch requests url and decodes the JSON response.
+func (c *JSONClient) fetch(ctx context.Context, url string) (any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range c.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch prices: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &monitor.StatusError{StatusCode: resp.StatusCode}
+	}
+
+	var body any
+	dec := json.NewDecoder(resp.Body)
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// jsonPath is a parsed path expression, a sequence of object keys and array indexes.
+type jsonPath []jsonPathStep
+
+// jsonPathStep is a single step of a jsonPath, either a key or, if key is empty, an index.
+type jsonPathStep struct {
+	key   string
+	index int
+}
+
+// parseJSONPath parses a JSONPath-like expression. Keys are separated by dots and array elements
+// are selected with [n] or, as in gjson, a numeric key. Keys containing dots are written as ['a.b'].
+// The leading $ is optional, e.g. $.data[0].price, data.0.price and data[0]['price'] are the same.
+func parseJSONPath(expr string) (jsonPath, error) {
+	s := strings.TrimPrefix(expr, "$")
+	var path jsonPath
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, "['"), strings.HasPrefix(s, `["`):
+			quote := s[1:2]
+			end := strings.Index(s[2:], quote+"]")
+			if end < 0 {
+				return nil, errors.Newf("path %q: unterminated key", expr)
+			}
+			path = append(path, jsonPathStep{key: s[2 : 2+end]})
+			s = s[2+end+2:]
+
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, errors.Newf("path %q: unterminated index", expr)
+			}
+			index, err := strconv.Atoi(s[1:end])
+			if err != nil || index < 0 {
+				return nil, errors.Newf("path %q: invalid index %q", expr, s[1:end])
+			}
+			path = append(path, jsonPathStep{index: index})
+			s = s[end+1:]
+
+		case s[0] == '.':
+			s = s[1:]
+			if s == "" || s[0] == '.' {
+				return nil, errors.Newf("path %q: empty key", expr)
+			}
+
+		default:
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			path = append(path, jsonPathStep{key: s[:end]})
+			s = s[end:]
+		}
+	}
+	if len(path) == 0 {
+		return nil, errors.Newf("path %q: empty path", expr)
+	}
+	return path, nil
+}
+
+// replace returns the path with the placeholders in its keys replaced by r.
+func (p jsonPath) replace(r *strings.Replacer) jsonPath {
+	replaced := make(jsonPath, len(p))
+	for i, step := range p {
+		replaced[i] = jsonPathStep{key: r.Replace(step.key), index: step.index}
+	}
+	return replaced
+}
+
+// eval returns the value at the path in v decoded by encoding/json, and reports whether it exists.
+func (p jsonPath) eval(v any) (any, bool) {
+	for _, step := range p {
+		switch node := v.(type) {
+		case map[string]any:
+			if step.key == "" {
+				return nil, false
+			}
+			if v = node[step.key]; v == nil {
+				return nil, false
+			}
+		case []any:
+			index := step.index
+			if step.key != "" {
+				var err error
+				if index, err = strconv.Atoi(step.key); err != nil {
+					return nil, false
+				}
+			}
+			if index < 0 || index >= len(node) {
+				return nil, false
+			}
+			v = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return v, v != nil
+}
+
+// jsonFloat converts a JSON number or numeric string to float64.
+func jsonFloat(v any) (float64, error) {
+	switch v := v.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	}
+	return 0, errors.Newf("unexpected %s value, expected a number or a string", jsonType(v))
+}
+
+// jsonType returns the JSON type name of v decoded by encoding/json.
+func jsonType(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
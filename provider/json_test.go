@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONClient_GetPrices(t *testing.T) {
+	registry := monitor.DefaultRegistry()
+	assert.NoError(t, registry.Register(monitor.CoinInfo{Symbol: "atom", IDs: map[string]string{"internal": "cosmos"}}))
+
+	tests := []struct {
+		name           string
+		url            string
+		path           string
+		ids            map[monitor.Pair]string
+		headers        map[string]string
+		scale          float64
+		pairs          monitor.Pairs
+		status         int
+		responses      map[string]string // responses are keyed by request URI
+		expectedPrices []monitor.PriceData
+		expectedError  string
+		expectedStatus int
+		cancelContext  bool
+	}{
+		{
+			name:      "price per request",
+			url:       "/price/{base}/{quote}",
+			path:      "$.data.price",
+			pairs:     monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}, {Base: "atom", Quote: monitor.USD}},
+			responses: map[string]string{"/price/osmo/usd": `{"data":{"price":"0.5231"}}`, "/price/cosmos/usd": `{"data":{"price":6.1}}`},
+			expectedPrices: []monitor.PriceData{
+				{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "Internal", Price: 0.5231},
+				{Pair: monitor.Pair{Base: "atom", Quote: monitor.USD}, Service: "Internal", Price: 6.1},
+			},
+		},
+		{
+			name:      "shared request",
+			url:       "/prices?quote={quote}",
+			path:      "prices['{id}'].last[0]",
+			ids:       map[monitor.Pair]string{{Base: monitor.OSMO, Quote: monitor.USD}: "osmo.usd", {Base: "atom", Quote: monitor.USD}: "atom.usd"},
+			headers:   map[string]string{"Authorization": "Bearer token"},
+			scale:     1e-6,
+			pairs:     monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}, {Base: "atom", Quote: monitor.USD}, {Base: "tia", Quote: monitor.USD}},
+			responses: map[string]string{"/prices?quote=usd": `{"prices":{"osmo.usd":{"last":[523100]},"atom.usd":{"last":["6100000"]}}}`},
+			expectedPrices: []monitor.PriceData{
+				{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "Internal", Price: 0.5231},
+				{Pair: monitor.Pair{Base: "atom", Quote: monitor.USD}, Service: "Internal", Price: 6.1},
+			},
+		},
+		{
+			name:      "ids are escaped by position",
+			url:       "/quote/{id}?sym={id}",
+			path:      "prices.{id}",
+			ids:       map[monitor.Pair]string{{Base: monitor.OSMO, Quote: monitor.USD}: "A&b=c"},
+			pairs:     monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			responses: map[string]string{"/quote/A&b=c?sym=A%26b%3Dc": `{"prices":{"A&b=c":0.5}}`},
+			expectedPrices: []monitor.PriceData{
+				{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "Internal", Price: 0.5},
+			},
+		},
+		{
+			name:      "dotted id is a single key",
+			url:       "/prices",
+			path:      "prices.{id}",
+			ids:       map[monitor.Pair]string{{Base: monitor.OSMO, Quote: monitor.USD}: "OSMO.USD"},
+			pairs:     monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			responses: map[string]string{"/prices": `{"prices":{"OSMO.USD":0.5}}`},
+			expectedPrices: []monitor.PriceData{
+				{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "Internal", Price: 0.5},
+			},
+		},
+		{
+			name:      "missing price",
+			url:       "/prices",
+			path:      "items.1.price",
+			pairs:     monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			responses: map[string]string{"/prices": `{"items":[{"price":1}]}`},
+		},
+		{
+			name:           "error response",
+			url:            "/prices",
+			path:           "price",
+			pairs:          monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			status:         http.StatusUnauthorized,
+			expectedError:  "unexpected status code: 401",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:          "unknown coin",
+			url:           "/prices",
+			path:          "price",
+			pairs:         monitor.Pairs{{Base: "doge", Quote: monitor.USD}},
+			expectedError: "unknown coin",
+		},
+		{
+			name:          "context cancelled",
+			url:           "/prices",
+			path:          "price",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			responses:     map[string]string{"/prices": `{"price":1}`},
+			expectedError: "context canceled",
+			cancelContext: true,
+		},
+		{
+			name:          "invalid price format",
+			url:           "/prices",
+			path:          "price",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			responses:     map[string]string{"/prices": `{"price":{"value":1}}`},
+			expectedError: "failed to parse price: unexpected object value, expected a number or a string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := make(map[string]int)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests[r.URL.RequestURI()]++
+				for k, v := range tt.headers {
+					assert.Equal(t, v, r.Header.Get(k))
+				}
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+					return
+				}
+				response, ok := tt.responses[r.URL.RequestURI()]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Write([]byte(response))
+			}))
+			defer server.Close()
+
+			client, err := NewJSONClient("Internal", server.URL+tt.url, tt.path, registry)
+			assert.NoError(t, err)
+			client.HTTPClient = server.Client()
+			client.IDs = tt.ids
+			client.Headers = tt.headers
+			if tt.scale != 0 {
+				client.Scale = tt.scale
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if tt.cancelContext {
+				cancel()
+			}
+
+			prices, err := client.GetPrices(ctx, tt.pairs)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				if tt.expectedStatus != 0 {
+					pe := &monitor.ProviderError{Provider: "Internal", Err: err}
+					assert.Equal(t, tt.expectedStatus, pe.StatusCode())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPrices, prices)
+				for uri, n := range requests {
+					assert.Equal(t, 1, n, "requests of %s", uri)
+				}
+			}
+		})
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		expr          string
+		expected      jsonPath
+		expectedError string
+	}{
+		{expr: "$.data.price", expected: jsonPath{{key: "data"}, {key: "price"}}},
+		{expr: "data[0].price", expected: jsonPath{{key: "data"}, {index: 0}, {key: "price"}}},
+		{expr: "data.0.price", expected: jsonPath{{key: "data"}, {key: "0"}, {key: "price"}}},
+		{expr: `$['osmo.usd']["last"][2]`, expected: jsonPath{{key: "osmo.usd"}, {key: "last"}, {index: 2}}},
+		{expr: "$", expectedError: `path "$": empty path`},
+		{expr: "data..price", expectedError: `path "data..price": empty key`},
+		{expr: "data[x]", expectedError: `path "data[x]": invalid index "x"`},
+		{expr: "data['price", expectedError: `path "data['price": unterminated key`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := parseJSONPath(tt.expr)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, path)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/errors"
+)
+
+// Kraken is the name of the Kraken provider, also used to look up coin identifiers in the registry.
+const Kraken = "Kraken"
+
+// KrakenClient represents the client to interact with the Kraken spot REST API.
+type KrakenClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Registry   *monitor.Registry
+	Pairs      map[monitor.Pair]string // Pairs maps pairs to Kraken pair names, pairs without one are the concatenation of coin identifiers, e.g. OSMOUSD
+}
+
+// NewKrakenClient creates a new instance of the KrakenClient.
+func NewKrakenClient(registry *monitor.Registry) *KrakenClient {
+	return &KrakenClient{
+		BaseURL:    "https://api.kraken.com",
+		HTTPClient: &http.Client{},
+		Registry:   registry,
+	}
+}
+
+// Name returns the name of the provider.
+func (c *KrakenClient) Name() string {
+	return Kraken
+}
+
+// Resolve returns why the pair can't be priced, nil if it can. It implements monitor.PairResolver.
+func (c *KrakenClient) Resolve(pair monitor.Pair) error {
+	if _, ok := c.Pairs[pair]; ok {
+		return nil
+	}
+	return resolveCoinIDs(c.Registry, pair, Kraken)
+}
+
+// GetPrices fetches the last trade prices of the pairs from the Kraken ticker.
+func (c *KrakenClient) GetPrices(ctx context.Context, cryptos monitor.Pairs) ([]monitor.PriceData, error) {
+	names := make([]string, len(cryptos))
+	for i, pair := range cryptos {
+		var err error
+		if names[i], err = c.pairName(pair); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(nonEmpty(names)) == 0 {
+		return nil, nil
+	}
+
+	url := fmt.Sprintf("%s/0/public/Ticker?pair=%s", c.BaseURL, url.QueryEscape(strings.Join(unique(nonEmpty(names)), ",")))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch prices: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &monitor.StatusError{StatusCode: resp.StatusCode}
+	}
+
+	// Errors are reported in the error array, with a 200 status code.
+	var body struct {
+		Error  []string `json:"error"`
+		Result map[string]struct {
+			Close []string `json:"c"` // Close is the price and the volume of the last trade
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if len(body.Error) > 0 {
+		return nil, errors.Newf("kraken: %s", strings.Join(body.Error, ", "))
+	}
+
+	var pricesData []monitor.PriceData
+	for i, pair := range cryptos {
+		if names[i] == "" {
+			continue
+		}
+		ticker, ok := body.Result[krakenResultKey(body.Result, names[i])]
+		if !ok || len(ticker.Close) == 0 {
+			continue
+		}
+		price, err := strconv.ParseFloat(ticker.Close[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse price: %w", err)
+		}
+		pricesData = append(pricesData, monitor.PriceData{
+			Pair:    pair,
+			Service: Kraken,
+			Price:   price,
+		})
+	}
+
+	return pricesData, nil
+}
+
+// pairName returns the Kraken pair name of the pair, or an empty string if its coins have no identifiers for Kraken.
+func (c *KrakenClient) pairName(pair monitor.Pair) (string, error) {
+	if name, ok := c.Pairs[pair]; ok {
+		return name, nil
+	}
+
+	base, quote, ok, err := coinIDs(c.Registry, pair, Kraken)
+	if err != nil || !ok {
+		return "", err
+	}
+	return strings.ToUpper(base + quote), nil
+}
+
+// krakenResultKey returns the key of the ticker of the requested pair name in result.
+// Kraken keys the result of legacy pairs by their full name, e.g. XBTUSD is returned as XXBTZUSD.
+func krakenResultKey[T any](result map[string]T, name string) string {
+	if _, ok := result[name]; ok {
+		return name
+	}
+	for key := range result {
+		if krakenAltName(key) == krakenAltName(name) {
+			return key
+		}
+	}
+	return name
+}
+
+// krakenAltName strips the X and Z asset class prefixes of legacy full pair names, e.g. XXBTZUSD is XBTUSD.
+func krakenAltName(name string) string {
+	if len(name) == 8 && strings.ContainsRune("XZ", rune(name[0])) && strings.ContainsRune("XZ", rune(name[4])) {
+		return name[1:4] + name[5:]
+	}
+	return name
+}
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKrakenClient_GetPrices(t *testing.T) {
+	tests := []struct {
+		name           string
+		pairs          monitor.Pairs
+		names          map[monitor.Pair]string
+		status         int
+		mockResponse   string
+		expectedQuery  string
+		expectedPrices []monitor.PriceData
+		expectedError  string
+		expectedStatus int
+		cancelContext  bool
+	}{
+		{
+			name:          "successful request",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			mockResponse:  `{"error":[],"result":{"OSMOUSD":{"a":["0.5240","1","1.000"],"b":["0.5230","1","1.000"],"c":["0.52310","12.5"]}}}`,
+			expectedQuery: "OSMOUSD",
+			expectedPrices: []monitor.PriceData{
+				{
+					Pair:    monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD},
+					Service: "Kraken",
+					Price:   0.5231,
+				},
+			},
+		},
+		{
+			name:          "legacy pair name",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}, {Base: "btc", Quote: monitor.USD}},
+			names:         map[monitor.Pair]string{{Base: "btc", Quote: monitor.USD}: "XBTUSD"},
+			mockResponse:  `{"error":[],"result":{"OSMOUSD":{"c":["0.5","1"]},"XXBTZUSD":{"c":["64000.1","0.01"]}}}`,
+			expectedQuery: "OSMOUSD,XBTUSD",
+			expectedPrices: []monitor.PriceData{
+				{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "Kraken", Price: 0.5},
+				{Pair: monitor.Pair{Base: "btc", Quote: monitor.USD}, Service: "Kraken", Price: 64000.1},
+			},
+		},
+		{
+			name:          "missing price",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			mockResponse:  `{"error":[],"result":{}}`,
+			expectedQuery: "OSMOUSD",
+		},
+		{
+			name:          "error array",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			mockResponse:  `{"error":["EQuery:Unknown asset pair"]}`,
+			expectedError: "kraken: EQuery:Unknown asset pair",
+		},
+		{
+			name:           "error response",
+			pairs:          monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			status:         http.StatusBadGateway,
+			expectedError:  "unexpected status code: 502",
+			expectedStatus: http.StatusBadGateway,
+		},
+		{
+			name:          "unknown coin",
+			pairs:         monitor.Pairs{{Base: "doge", Quote: monitor.USD}},
+			expectedError: "unknown coin",
+		},
+		{
+			name:          "context cancelled",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			mockResponse:  `{"error":[],"result":{}}`,
+			expectedError: "context canceled",
+			cancelContext: true,
+		},
+		{
+			name:          "invalid price format",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			mockResponse:  `{"error":[],"result":{"OSMOUSD":{"c":["invalid","1"]}}}`,
+			expectedError: "failed to parse price",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/0/public/Ticker", r.URL.Path)
+				if tt.expectedQuery != "" {
+					assert.Equal(t, tt.expectedQuery, r.URL.Query().Get("pair"))
+				}
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			client := NewKrakenClient(monitor.DefaultRegistry())
+			client.BaseURL = server.URL
+			client.HTTPClient = server.Client()
+			client.Pairs = tt.names
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if tt.cancelContext {
+				cancel()
+			}
+
+			prices, err := client.GetPrices(ctx, tt.pairs)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				if tt.expectedStatus != 0 {
+					pe := &monitor.ProviderError{Provider: Kraken, Err: err}
+					assert.Equal(t, tt.expectedStatus, pe.StatusCode())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPrices, prices)
+			}
+		})
+	}
+}
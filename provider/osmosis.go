@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/errors"
+)
+
+// Osmosis is the name of the Osmosis provider, also used to look up coin denoms in the registry.
+const Osmosis = "Osmosis"
+
+// OsmosisClient represents the client to query spot prices of Osmosis pools from an LCD (REST) endpoint.
+// Unlike SQS, which routes over pools off-chain, it reports the spot price of a single pool as seen by the chain.
+type OsmosisClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Registry   *monitor.Registry
+	Pools      map[monitor.Pair]string // Pools maps pairs to the IDs of the pools to price them with, pairs without one are left out
+}
+
+// NewOsmosisClient creates a new instance of the OsmosisClient querying the pools on the LCD endpoint at baseURL.
+func NewOsmosisClient(baseURL string, pools map[monitor.Pair]string, registry *monitor.Registry) *OsmosisClient {
+	return &OsmosisClient{
+		BaseURL:    baseURL,
+		HTTPClient: &http.Client{},
+		Registry:   registry,
+		Pools:      pools,
+	}
+}
+
+// Name returns the name of the provider.
+func (c *OsmosisClient) Name() string {
+	return Osmosis
+}
+
+// OsmosisError represents an error returned by the LCD endpoint.
+type OsmosisError struct {
+	StatusCode int
+	Code       int
+	Message    string
+}
+
+// Error implements the error interface.
+func (e *OsmosisError) Error() string {
+	return fmt.Sprintf("osmosis: %s (code %d, status %d)", e.Message, e.Code, e.StatusCode)
+}
+
+// Unwrap returns the underlying StatusError so the status code of the failure is preserved.
+func (e *OsmosisError) Unwrap() error {
+	return &monitor.StatusError{StatusCode: e.StatusCode}
+}
+
+// Resolve returns why the pair can't be priced, nil if it can. It implements monitor.PairResolver.
+func (c *OsmosisClient) Resolve(pair monitor.Pair) error {
+	if _, ok := c.Pools[pair]; !ok {
+		return errors.Newf("no pool id for pair %s", pair)
+	}
+	return resolveCoinIDs(c.Registry, pair, Osmosis)
+}
+
+// GetPrices fetches the spot prices of the configured pools, one request per pool,
+// and converts them from the smallest units to whole coins using the coin decimals.
+func (c *OsmosisClient) GetPrices(ctx context.Context, cryptos monitor.Pairs) ([]monitor.PriceData, error) {
+	var pricesData []monitor.PriceData
+	for _, pair := range cryptos {
+		pool, ok := c.Pools[pair]
+		if !ok {
+			continue
+		}
+
+		base, err := c.Registry.Lookup(pair.Base)
+		if err != nil {
+			return nil, err
+		}
+		quote, err := c.Registry.Lookup(pair.Quote)
+		if err != nil {
+			return nil, err
+		}
+		baseDenom, quoteDenom, ok, err := coinIDs(c.Registry, pair, Osmosis)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		spotPrice, err := c.spotPrice(ctx, pool, baseDenom, quoteDenom)
+		if err != nil {
+			return nil, fmt.Errorf("pool %s: %w", pool, err)
+		}
+
+		pricesData = append(pricesData, monitor.PriceData{
+			Pair:    pair,
+			Service: Osmosis,
+			Price:   scale(spotPrice, base.Decimals-quote.Decimals),
+		})
+	}
+
+	return pricesData, nil
+}
+
+// spotPrice queries the spot price of the pool, in smallest units of the quote denom per smallest unit of the base denom.
+func (c *OsmosisClient) spotPrice(ctx context.Context, pool, baseDenom, quoteDenom string) (float64, error) {
+	query := url.Values{"base_asset_denom": {baseDenom}, "quote_asset_denom": {quoteDenom}}
+	url := fmt.Sprintf("%s/osmosis/poolmanager/v1beta1/pools/%s/prices?%s", c.BaseURL, url.PathEscape(pool), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch prices: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Message == "" {
+			return 0, &monitor.StatusError{StatusCode: resp.StatusCode}
+		}
+		return 0, &OsmosisError{StatusCode: resp.StatusCode, Code: body.Code, Message: body.Message}
+	}
+
+	var body struct {
+		SpotPrice string `json:"spot_price"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, err
+	}
+
+	price, err := strconv.ParseFloat(body.SpotPrice, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse price: %w", err)
+	}
+	return price, nil
+}
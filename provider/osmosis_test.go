@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOsmosisClient_GetPrices(t *testing.T) {
+	registry := monitor.DefaultRegistry()
+	assert.NoError(t, registry.Register(monitor.CoinInfo{Symbol: "weth", Decimals: 18, IDs: map[string]string{"osmosis": "ibc/WETH"}}))
+
+	tests := []struct {
+		name           string
+		pairs          monitor.Pairs
+		pools          map[monitor.Pair]string
+		status         int
+		mockResponse   string
+		expectedPath   string
+		expectedBase   string
+		expectedQuote  string
+		expectedPrices []monitor.PriceData
+		expectedError  string
+		expectedStatus int
+		cancelContext  bool
+	}{
+		{
+			name:          "successful request",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			pools:         map[monitor.Pair]string{{Base: monitor.OSMO, Quote: monitor.USD}: "1464"},
+			mockResponse:  `{"spot_price":"0.523100000000000000"}`,
+			expectedPath:  "/osmosis/poolmanager/v1beta1/pools/1464/prices",
+			expectedBase:  "uosmo",
+			expectedQuote: usdcDenom,
+			expectedPrices: []monitor.PriceData{
+				{
+					Pair:    monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD},
+					Service: "Osmosis",
+					Price:   0.5231,
+				},
+			},
+		},
+		{
+			name:          "decimals conversion",
+			pairs:         monitor.Pairs{{Base: "weth", Quote: monitor.USD}},
+			pools:         map[monitor.Pair]string{{Base: "weth", Quote: monitor.USD}: "1948"},
+			mockResponse:  `{"spot_price":"0.000000003000000000"}`,
+			expectedPath:  "/osmosis/poolmanager/v1beta1/pools/1948/prices",
+			expectedBase:  "ibc/WETH",
+			expectedQuote: usdcDenom,
+			expectedPrices: []monitor.PriceData{
+				{Pair: monitor.Pair{Base: "weth", Quote: monitor.USD}, Service: "Osmosis", Price: 3000},
+			},
+		},
+		{
+			name:  "pair without pool",
+			pairs: monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+		},
+		{
+			name:           "error json",
+			pairs:          monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			pools:          map[monitor.Pair]string{{Base: monitor.OSMO, Quote: monitor.USD}: "1"},
+			status:         http.StatusBadRequest,
+			mockResponse:   `{"code":3,"message":"denom uosmo does not exist in pool","details":[]}`,
+			expectedError:  "pool 1: osmosis: denom uosmo does not exist in pool (code 3, status 400)",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "error response",
+			pairs:          monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			pools:          map[monitor.Pair]string{{Base: monitor.OSMO, Quote: monitor.USD}: "1"},
+			status:         http.StatusServiceUnavailable,
+			expectedError:  "pool 1: unexpected status code: 503",
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:          "unknown coin",
+			pairs:         monitor.Pairs{{Base: "doge", Quote: monitor.USD}},
+			pools:         map[monitor.Pair]string{{Base: "doge", Quote: monitor.USD}: "1"},
+			expectedError: "unknown coin",
+		},
+		{
+			name:          "context cancelled",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			pools:         map[monitor.Pair]string{{Base: monitor.OSMO, Quote: monitor.USD}: "1"},
+			expectedError: "context canceled",
+			cancelContext: true,
+		},
+		{
+			name:          "invalid price format",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			pools:         map[monitor.Pair]string{{Base: monitor.OSMO, Quote: monitor.USD}: "1"},
+			mockResponse:  `{"spot_price":"invalid"}`,
+			expectedError: "failed to parse price",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.expectedPath != "" {
+					assert.Equal(t, tt.expectedPath, r.URL.Path)
+					assert.Equal(t, tt.expectedBase, r.URL.Query().Get("base_asset_denom"))
+					assert.Equal(t, tt.expectedQuote, r.URL.Query().Get("quote_asset_denom"))
+				}
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			client := NewOsmosisClient(server.URL, tt.pools, registry)
+			client.HTTPClient = server.Client()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if tt.cancelContext {
+				cancel()
+			}
+
+			prices, err := client.GetPrices(ctx, tt.pairs)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				if tt.expectedStatus != 0 {
+					pe := &monitor.ProviderError{Provider: Osmosis, Err: err}
+					assert.Equal(t, tt.expectedStatus, pe.StatusCode())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPrices, prices)
+			}
+		})
+	}
+}
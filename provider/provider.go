@@ -0,0 +1,70 @@
+// Package provider implements monitor.Provider clients for external price sources.
+package provider
+
+import (
+	"math"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/errors"
+)
+
+// unique returns ids with duplicates removed, preserving the order of first occurrence.
+func unique(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// scale returns v scaled by 10^exp. Negative exponents divide by the exact power of ten,
+// so that e.g. 5231 scaled by -4 is exactly 0.5231.
+func scale(v float64, exp int) float64 {
+	if exp < 0 {
+		return v / math.Pow10(-exp)
+	}
+	return v * math.Pow10(exp)
+}
+
+// coinIDs returns the identifiers the provider uses for the base and quote coins of the pair.
+// It reports false if either coin has no identifier for the provider, such pairs are skipped
+// so that they are reported missing rather than failing the prices of every other pair.
+func coinIDs(registry *monitor.Registry, pair monitor.Pair, provider string) (base, quote string, ok bool, err error) {
+	if base, err = registry.ID(pair.Base, provider); err != nil {
+		if errors.Is(err, monitor.ErrNoIdentifier) {
+			return "", "", false, nil
+		}
+		return "", "", false, err
+	}
+	if quote, err = registry.ID(pair.Quote, provider); err != nil {
+		if errors.Is(err, monitor.ErrNoIdentifier) {
+			return "", "", false, nil
+		}
+		return "", "", false, err
+	}
+	return base, quote, true, nil
+}
+
+// resolveCoinIDs returns why the provider can't price the pair, nil if both of its coins have identifiers for it.
+func resolveCoinIDs(registry *monitor.Registry, pair monitor.Pair, provider string) error {
+	if _, err := registry.ID(pair.Base, provider); err != nil {
+		return err
+	}
+	_, err := registry.ID(pair.Quote, provider)
+	return err
+}
+
+// nonEmpty returns ids without empty identifiers, those of skipped pairs.
+func nonEmpty(ids []string) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != "" {
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/deividaspetraitis/price-monitor/errors"
+)
+
+// Pyth is the name of the Pyth provider.
+const Pyth = "Pyth"
+
+// PythClient represents the client to fetch Pyth Network oracle prices from a Hermes endpoint.
+type PythClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Feeds      map[monitor.Pair]string // Feeds maps pairs to hex encoded price feed IDs, pairs without one are left out
+}
+
+// NewPythClient creates a new instance of the PythClient fetching the given price feeds.
+func NewPythClient(feeds map[monitor.Pair]string) *PythClient {
+	return &PythClient{
+		BaseURL:    "https://hermes.pyth.network",
+		HTTPClient: &http.Client{},
+		Feeds:      feeds,
+	}
+}
+
+// Name returns the name of the provider.
+func (c *PythClient) Name() string {
+	return Pyth
+}
+
+// Resolve returns why the pair can't be priced, nil if it can. It implements monitor.PairResolver.
+func (c *PythClient) Resolve(pair monitor.Pair) error {
+	if _, ok := c.Feeds[pair]; !ok {
+		return errors.Newf("no price feed id for pair %s", pair)
+	}
+	return nil
+}
+
+// GetPrices fetches the latest prices of the feeds of the pairs, along with their confidence intervals and publish times.
+func (c *PythClient) GetPrices(ctx context.Context, cryptos monitor.Pairs) ([]monitor.PriceData, error) {
+	query := url.Values{}
+	feeds := make([]string, len(cryptos))
+	for i, pair := range cryptos {
+		feed, ok := c.Feeds[pair]
+		if !ok {
+			continue
+		}
+		feeds[i] = PythFeedID(feed)
+		// Pairs may share a feed, it is requested once as Hermes rejects or echoes repeated ids.
+		if !slices.Contains(query["ids[]"], feeds[i]) {
+			query.Add("ids[]", feeds[i])
+		}
+	}
+	if len(query) == 0 {
+		return nil, nil
+	}
+	query.Set("parsed", "true")
+
+	url := fmt.Sprintf("%s/v2/updates/price/latest?%s", c.BaseURL, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch prices: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &monitor.StatusError{StatusCode: resp.StatusCode}
+	}
+
+	var body struct {
+		Parsed []struct {
+			ID    string `json:"id"`
+			Price struct {
+				Price       string `json:"price"`
+				Conf        string `json:"conf"`
+				Expo        int    `json:"expo"`
+				PublishTime int64  `json:"publish_time"`
+			} `json:"price"`
+		} `json:"parsed"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	var pricesData []monitor.PriceData
+	for i, pair := range cryptos {
+		if feeds[i] == "" {
+			continue
+		}
+		for _, update := range body.Parsed {
+			if PythFeedID(update.ID) != feeds[i] {
+				continue
+			}
+			price, err := strconv.ParseInt(update.Price.Price, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse price: %w", err)
+			}
+			conf, err := strconv.ParseUint(update.Price.Conf, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse confidence: %w", err)
+			}
+			pricesData = append(pricesData, monitor.PriceData{
+				Pair:       pair,
+				Service:    Pyth,
+				Price:      scale(float64(price), update.Price.Expo),
+				Confidence: scale(float64(conf), update.Price.Expo),
+				UpdatedAt:  time.Unix(update.Price.PublishTime, 0).UTC(),
+			})
+			break
+		}
+	}
+
+	return pricesData, nil
+}
+
+// PythFeedID normalizes a hex encoded price feed ID to lower case without the 0x prefix, as returned by Hermes.
+func PythFeedID(id string) string {
+	return strings.TrimPrefix(strings.ToLower(id), "0x")
+}
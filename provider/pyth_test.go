@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	osmoFeed = "5867f5683c757393a0670ef0f701490950fe93fdb006d181c8265a831ac0c5c6"
+	atomFeed = "b00b60f88b03a6a625a8d1c048c3f66653edf217439983d037e7222c4e612819"
+)
+
+func TestPythClient_GetPrices(t *testing.T) {
+	tests := []struct {
+		name           string
+		pairs          monitor.Pairs
+		feeds          map[monitor.Pair]string
+		status         int
+		mockResponse   string
+		expectedIDs    []string
+		expectedPrices []monitor.PriceData
+		expectedError  string
+		expectedStatus int
+		cancelContext  bool
+	}{
+		{
+			name:         "successful request",
+			pairs:        monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			feeds:        map[monitor.Pair]string{{Base: monitor.OSMO, Quote: monitor.USD}: "0x" + osmoFeed},
+			mockResponse: `{"binary":{"encoding":"hex","data":[]},"parsed":[{"id":"` + osmoFeed + `","price":{"price":"52310000","conf":"25000","expo":-8,"publish_time":1714564800},"ema_price":{"price":"52000000","conf":"30000","expo":-8,"publish_time":1714564800}}]}`,
+			expectedIDs:  []string{osmoFeed},
+			expectedPrices: []monitor.PriceData{
+				{
+					Pair:       monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD},
+					Service:    "Pyth",
+					Price:      0.5231,
+					Confidence: 0.00025,
+					UpdatedAt:  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name:  "several feeds",
+			pairs: monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}, {Base: "atom", Quote: monitor.USD}, {Base: "tia", Quote: monitor.USD}},
+			feeds: map[monitor.Pair]string{
+				{Base: monitor.OSMO, Quote: monitor.USD}: osmoFeed,
+				{Base: "atom", Quote: monitor.USD}:       "0x" + atomFeed,
+			},
+			mockResponse: `{"parsed":[
+				{"id":"` + atomFeed + `","price":{"price":"612","conf":"1","expo":-2,"publish_time":1714564801}},
+				{"id":"` + osmoFeed + `","price":{"price":"5","conf":"0","expo":-1,"publish_time":1714564800}}
+			]}`,
+			expectedIDs: []string{osmoFeed, atomFeed},
+			expectedPrices: []monitor.PriceData{
+				{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "Pyth", Price: 0.5, UpdatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+				{Pair: monitor.Pair{Base: "atom", Quote: monitor.USD}, Service: "Pyth", Price: 6.12, Confidence: 0.01, UpdatedAt: time.Date(2024, 5, 1, 12, 0, 1, 0, time.UTC)},
+			},
+		},
+		{
+			name:  "pairs sharing a feed",
+			pairs: monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}, {Base: "uosmo", Quote: monitor.USD}},
+			feeds: map[monitor.Pair]string{
+				{Base: monitor.OSMO, Quote: monitor.USD}: osmoFeed,
+				{Base: "uosmo", Quote: monitor.USD}:      "0x" + osmoFeed,
+			},
+			mockResponse: `{"parsed":[{"id":"` + osmoFeed + `","price":{"price":"5","conf":"0","expo":-1,"publish_time":1714564800}}]}`,
+			expectedIDs:  []string{osmoFeed},
+			expectedPrices: []monitor.PriceData{
+				{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "Pyth", Price: 0.5, UpdatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+				{Pair: monitor.Pair{Base: "uosmo", Quote: monitor.USD}, Service: "Pyth", Price: 0.5, UpdatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:  "pair without feed",
+			pairs: monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+		},
+		{
+			name:           "error response",
+			pairs:          monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			feeds:          map[monitor.Pair]string{{Base: monitor.OSMO, Quote: monitor.USD}: osmoFeed},
+			status:         http.StatusNotFound,
+			mockResponse:   `Price ids not found: ` + osmoFeed,
+			expectedError:  "unexpected status code: 404",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:          "context cancelled",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			feeds:         map[monitor.Pair]string{{Base: monitor.OSMO, Quote: monitor.USD}: osmoFeed},
+			mockResponse:  `{"parsed":[]}`,
+			expectedError: "context canceled",
+			cancelContext: true,
+		},
+		{
+			name:          "invalid price format",
+			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
+			feeds:         map[monitor.Pair]string{{Base: monitor.OSMO, Quote: monitor.USD}: osmoFeed},
+			mockResponse:  `{"parsed":[{"id":"` + osmoFeed + `","price":{"price":"0.52","conf":"1","expo":-8,"publish_time":1714564800}}]}`,
+			expectedError: "failed to parse price",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v2/updates/price/latest", r.URL.Path)
+				if tt.expectedIDs != nil {
+					assert.Equal(t, tt.expectedIDs, r.URL.Query()["ids[]"])
+				}
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			client := NewPythClient(tt.feeds)
+			client.BaseURL = server.URL
+			client.HTTPClient = server.Client()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if tt.cancelContext {
+				cancel()
+			}
+
+			prices, err := client.GetPrices(ctx, tt.pairs)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				if tt.expectedStatus != 0 {
+					pe := &monitor.ProviderError{Provider: Pyth, Err: err}
+					assert.Equal(t, tt.expectedStatus, pe.StatusCode())
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedPrices, prices)
+			}
+		})
+	}
+}
@@ -11,51 +11,50 @@ import (
 	"github.com/deividaspetraitis/price-monitor"
 )
 
+// SQS is the name of the SQS provider, also used to look up coin identifiers in the registry.
+const SQS = "SQS"
+
 // SQSClient represents the client to interact with the SQS API.
 type SQSClient struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	Registry   *monitor.Registry
 }
 
 // NewSQSClient creates a new instance of the SQSClient.
-func NewSQSClient(baseURL string) *SQSClient {
+func NewSQSClient(baseURL string, registry *monitor.Registry) *SQSClient {
 	return &SQSClient{
 		BaseURL:    baseURL,
 		HTTPClient: &http.Client{},
+		Registry:   registry,
 	}
 }
 
-// SQSCoin represents a cryptocurrency in the SQS API.
-type SQSCoin struct {
-	Coin monitor.Coin
-}
-
-// String returns the string representation of the SQSCoin.
-func (c SQSCoin) String() string {
-	switch c.Coin {
-	case monitor.OSMO:
-		return "uosmo"
-	case monitor.USD:
-		return defaultQuote
-	}
-	return ""
+// Name returns the name of the provider.
+func (s *SQSClient) Name() string {
+	return SQS
 }
 
-// NewSQSCoin creates a new instance of the SQSCoin.
-func NewSQSCoin(coin monitor.Coin) SQSCoin {
-	return SQSCoin{Coin: coin}
+// Resolve returns why the pair can't be priced, nil if it can. It implements monitor.PairResolver.
+func (s *SQSClient) Resolve(pair monitor.Pair) error {
+	return resolveCoinIDs(s.Registry, pair, SQS)
 }
 
-const defaultQuote = "ibc/498A0751C798A0D9A389AA3691123DADA57DAA4FE165D5C75894505B876BA6E4"
-
 // GetPrices fetches the prices of cryptocurrencies from the SQS API.
 func (s *SQSClient) GetPrices(ctx context.Context, cryptos monitor.Pairs) ([]monitor.PriceData, error) {
 	baseCoins := make([]string, len(cryptos))
+	quoteCoins := make([]string, len(cryptos))
 	for i, pair := range cryptos {
-		baseCoins[i] = NewSQSCoin(pair.Base).String()
+		var err error
+		if baseCoins[i], quoteCoins[i], _, err = coinIDs(s.Registry, pair, SQS); err != nil {
+			return nil, err
+		}
+	}
+	if len(nonEmpty(baseCoins)) == 0 {
+		return nil, nil
 	}
 
-	url := fmt.Sprintf("%s/tokens/prices?base=%s", s.BaseURL, strings.Join(baseCoins, ","))
+	url := fmt.Sprintf("%s/tokens/prices?base=%s", s.BaseURL, strings.Join(unique(nonEmpty(baseCoins)), ","))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -69,7 +68,7 @@ func (s *SQSClient) GetPrices(ctx context.Context, cryptos monitor.Pairs) ([]mon
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &monitor.StatusError{StatusCode: resp.StatusCode}
 	}
 
 	var rawPrices map[string]map[string]string
@@ -78,16 +77,15 @@ func (s *SQSClient) GetPrices(ctx context.Context, cryptos monitor.Pairs) ([]mon
 	}
 
 	var pricesData []monitor.PriceData
-	for _, pair := range cryptos {
-		baseCoin := NewSQSCoin(pair.Base).String()
-		if priceStr, ok := rawPrices[baseCoin][defaultQuote]; ok {
+	for i, pair := range cryptos {
+		if priceStr, ok := rawPrices[baseCoins[i]][quoteCoins[i]]; ok {
 			price, err := strconv.ParseFloat(priceStr, 64)
 			if err != nil {
 				return nil, fmt.Errorf("failed to parse price: %w", err)
 			}
 			pricesData = append(pricesData, monitor.PriceData{
 				Pair:    pair,
-				Service: "SQS",
+				Service: SQS,
 				Price:   price,
 			})
 		}
@@ -12,11 +12,17 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+const usdcDenom = "ibc/498A0751C798A0D9A389AA3691123DADA57DAA4FE165D5C75894505B876BA6E4"
+
 func TestSQSClient_GetPrices(t *testing.T) {
+	registry := monitor.DefaultRegistry()
+	assert.NoError(t, registry.Register(monitor.CoinInfo{Symbol: "atom", IDs: map[string]string{"coingecko": "cosmos"}}))
+
 	tests := []struct {
 		name           string
 		pairs          monitor.Pairs
 		mockResponse   map[string]map[string]string
+		expectedBase   string
 		expectedPrices []monitor.PriceData
 		expectedError  string
 		cancelContext  bool
@@ -25,7 +31,7 @@ func TestSQSClient_GetPrices(t *testing.T) {
 			name:  "successful request",
 			pairs: monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
 			mockResponse: map[string]map[string]string{
-				"uosmo": {defaultQuote: "1.23"},
+				"uosmo": {usdcDenom: "1.23"},
 			},
 			expectedPrices: []monitor.PriceData{
 				{
@@ -45,10 +51,27 @@ func TestSQSClient_GetPrices(t *testing.T) {
 			name:  "missing price",
 			pairs: monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
 			mockResponse: map[string]map[string]string{
-				"uatom": {defaultQuote: "10.5"},
+				"uatom": {usdcDenom: "10.5"},
 			},
 			expectedPrices: nil,
 		},
+		{
+			name:  "coin without identifier is skipped",
+			pairs: monitor.Pairs{{Base: "atom", Quote: monitor.USD}, {Base: monitor.OSMO, Quote: monitor.USD}},
+			mockResponse: map[string]map[string]string{
+				"uosmo": {usdcDenom: "1.23"},
+			},
+			expectedBase: "uosmo",
+			expectedPrices: []monitor.PriceData{
+				{Pair: monitor.Pair{Base: monitor.OSMO, Quote: monitor.USD}, Service: "SQS", Price: 1.23},
+			},
+		},
+		{
+			name:          "unknown coin",
+			pairs:         monitor.Pairs{{Base: "doge", Quote: monitor.USD}},
+			mockResponse:  map[string]map[string]string{},
+			expectedError: "unknown coin",
+		},
 		{
 			name:          "context cancelled",
 			pairs:         monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
@@ -60,7 +83,7 @@ func TestSQSClient_GetPrices(t *testing.T) {
 			name:  "invalid price format",
 			pairs: monitor.Pairs{{Base: monitor.OSMO, Quote: monitor.USD}},
 			mockResponse: map[string]map[string]string{
-				"uosmo": {defaultQuote: "invalid"},
+				"uosmo": {usdcDenom: "invalid"},
 			},
 			expectedError: "failed to parse price",
 		},
@@ -69,6 +92,9 @@ func TestSQSClient_GetPrices(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.expectedBase != "" {
+					assert.Equal(t, tt.expectedBase, r.URL.Query().Get("base"))
+				}
 				if tt.mockResponse == nil {
 					w.WriteHeader(http.StatusInternalServerError)
 					return
@@ -81,6 +107,7 @@ func TestSQSClient_GetPrices(t *testing.T) {
 			client := &SQSClient{
 				BaseURL:    server.URL,
 				HTTPClient: server.Client(),
+				Registry:   registry,
 			}
 
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,76 @@
+package monitor
+
+import (
+	"strings"
+)
+
+// compareReference compares the price of the rule subject with the price of its reference and returns
+// the difference if it exceeds the rule threshold, the comparison within it otherwise. The first reference
+// with a price is used, falling back to the next one when it is missing. Without configured references the subject is compared with every other provider.
+// Lower priority references skipped as a preferred one answered are returned with the comparisons within the
+// threshold if the subject is within it of them too, so that differences raised while falling back to them clear.
+// The deviation is relative to the reference price and PriceA is always the subject price.
+func compareReference(pair Pair, ps []PriceData, rule Rule) (diffs, within []PriceDifference) {
+	find := func(service string) (PriceData, bool) {
+		for _, p := range ps {
+			if strings.EqualFold(p.Service, service) {
+				return p, true
+			}
+		}
+		return PriceData{}, false
+	}
+
+	subject, ok := find(rule.Subject)
+	if !ok {
+		return nil, nil
+	}
+
+	var references, skipped []PriceData
+	if len(rule.References) == 0 {
+		for _, p := range ps {
+			if !strings.EqualFold(p.Service, rule.Subject) {
+				references = append(references, p)
+			}
+		}
+	}
+	for _, service := range rule.References {
+		if ref, ok := find(service); ok {
+			if len(references) == 0 {
+				references = append(references, ref)
+			} else {
+				skipped = append(skipped, ref)
+			}
+		}
+	}
+
+	compare := func(ref PriceData) (PriceDifference, bool) {
+		diff, rel := deviation(subject.Price, ref.Price, ref.Price)
+		return PriceDifference{
+			Pair:        pair,
+			ServiceA:    subject.Service,
+			ServiceB:    ref.Service,
+			PriceA:      subject.Price,
+			PriceB:      ref.Price,
+			ObservedAtA: subject.ObservedAt,
+			ObservedAtB: ref.ObservedAt,
+			Difference:  diff,
+			Relative:    rel,
+			Direction:   direction(subject.Price, ref.Price),
+			Rule:        rule,
+		}, rule.exceededBeyond(diff, rel, subject.Confidence+ref.Confidence)
+	}
+
+	for _, ref := range references {
+		if d, exceeded := compare(ref); exceeded {
+			diffs = append(diffs, d)
+		} else {
+			within = append(within, d)
+		}
+	}
+	for _, ref := range skipped {
+		if d, exceeded := compare(ref); !exceeded {
+			within = append(within, d)
+		}
+	}
+	return diffs, within
+}
@@ -0,0 +1,121 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare_Reference(t *testing.T) {
+	osmo := Pair{Base: OSMO, Quote: USD}
+	rule := Rule{Threshold: 1, Mode: Percent, Strategy: Reference, Subject: "SQS", References: []string{"Binance", "CoinGecko"}, Enabled: true}
+
+	tests := []struct {
+		name     string
+		prices   []PriceData
+		rule     Rule
+		expected []PriceDifference
+	}{
+		{
+			name: "subject over primary reference",
+			prices: []PriceData{
+				{Pair: osmo, Service: "CoinGecko", Price: 0.19},
+				{Pair: osmo, Service: "SQS", Price: 0.21},
+				{Pair: osmo, Service: "Binance", Price: 0.20},
+			},
+			rule: rule,
+			expected: []PriceDifference{
+				{
+					Pair:       osmo,
+					ServiceA:   "SQS",
+					ServiceB:   "Binance",
+					PriceA:     0.21,
+					PriceB:     0.20,
+					Difference: 0.009999999999999981,
+					Relative:   0.049999999999999906,
+					Direction:  Over,
+					Rule:       rule,
+				},
+			},
+		},
+		{
+			name: "fallback to secondary reference",
+			prices: []PriceData{
+				{Pair: osmo, Service: "CoinGecko", Price: 0.22},
+				{Pair: osmo, Service: "SQS", Price: 0.21},
+			},
+			rule: rule,
+			expected: []PriceDifference{
+				{
+					Pair:       osmo,
+					ServiceA:   "SQS",
+					ServiceB:   "CoinGecko",
+					PriceA:     0.21,
+					PriceB:     0.22,
+					Difference: 0.010000000000000009,
+					Relative:   0.0454545454545455,
+					Direction:  Under,
+					Rule:       rule,
+				},
+			},
+		},
+		{
+			name: "missing subject",
+			prices: []PriceData{
+				{Pair: osmo, Service: "CoinGecko", Price: 0.22},
+				{Pair: osmo, Service: "Binance", Price: 0.20},
+			},
+			rule:     rule,
+			expected: nil,
+		},
+		{
+			name: "every other provider is a reference when none are configured",
+			prices: []PriceData{
+				{Pair: osmo, Service: "CoinGecko", Price: 0.20},
+				{Pair: osmo, Service: "SQS", Price: 0.20},
+				{Pair: osmo, Service: "Binance", Price: 0.30},
+			},
+			rule: Rule{Threshold: 1, Mode: Percent, Strategy: Reference, Subject: "sqs", Enabled: true},
+			expected: []PriceDifference{
+				{
+					Pair:       osmo,
+					ServiceA:   "SQS",
+					ServiceB:   "Binance",
+					PriceA:     0.20,
+					PriceB:     0.30,
+					Difference: 0.09999999999999998,
+					Relative:   0.33333333333333326,
+					Direction:  Under,
+					Rule:       Rule{Threshold: 1, Mode: Percent, Strategy: Reference, Subject: "sqs", Enabled: true},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Compare(tt.prices, Rules{Default: tt.rule})
+			assert.Equal(t, tt.expected, result.Differences)
+		})
+	}
+}
+
+func TestCompare_ReferenceWithin(t *testing.T) {
+	osmo := Pair{Base: OSMO, Quote: USD}
+	rule := Rule{Threshold: 1, Mode: Percent, Strategy: Reference, Subject: "SQS", References: []string{"Binance", "CoinGecko", "Kraken"}, Enabled: true}
+
+	// Skipped lower priority references are reported within the threshold only if the subject is within it of them.
+	result := Compare([]PriceData{
+		{Pair: osmo, Service: "SQS", Price: 0.20},
+		{Pair: osmo, Service: "Binance", Price: 0.20},
+		{Pair: osmo, Service: "CoinGecko", Price: 0.20},
+		{Pair: osmo, Service: "Kraken", Price: 0.30},
+	}, Rules{Default: rule})
+	assert.Empty(t, result.Differences)
+
+	var references []string
+	for _, w := range result.Within {
+		references = append(references, w.ServiceB)
+	}
+	assert.Equal(t, []string{"Binance", "CoinGecko"}, references)
+}
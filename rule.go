@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/deividaspetraitis/price-monitor/errors"
+)
+
+// ThresholdMode determines how a price deviation is measured against a threshold.
+type ThresholdMode string
+
+// List of supported threshold modes.
+const (
+	Absolute    ThresholdMode = "absolute" // Absolute difference, in units of the quote coin
+	Percent     ThresholdMode = "percent"  // Relative difference in percent of the reference price
+	BasisPoints ThresholdMode = "bps"      // Relative difference in basis points of the reference price
+)
+
+// ParseThresholdMode parses s into a ThresholdMode. An empty string is Absolute.
+func ParseThresholdMode(s string) (ThresholdMode, error) {
+	switch m := ThresholdMode(s); m {
+	case "":
+		return Absolute, nil
+	case Absolute, Percent, BasisPoints:
+		return m, nil
+	}
+	return "", errors.Newf("unknown threshold mode %q", s)
+}
+
+// Strategy determines how prices of a pair reported by several providers are compared.
+type Strategy string
+
+// List of supported comparison strategies.
+const (
+	Pairwise    Strategy = "pairwise"     // Every price is compared with every other price
+	Median      Strategy = "median"       // Every price is compared with the median of all prices
+	TrimmedMean Strategy = "trimmed_mean" // Every price is compared with the mean of all prices but the lowest and the highest
+	Reference   Strategy = "reference"    // The price of the subject provider is compared with the price of reference providers
+)
+
+// ParseStrategy parses s into a Strategy. An empty string is Pairwise.
+func ParseStrategy(s string) (Strategy, error) {
+	switch st := Strategy(s); st {
+	case "":
+		return Pairwise, nil
+	case Pairwise, Median, TrimmedMean, Reference:
+		return st, nil
+	}
+	return "", errors.Newf("unknown strategy %q", s)
+}
+
+// consensus reports whether the strategy compares prices with a consensus price.
+func (s Strategy) consensus() bool {
+	return s == Median || s == TrimmedMean
+}
+
+// Rule configures how prices of a Pair are compared.
+// The zero value is a disabled rule.
+type Rule struct {
+	Threshold    float64       // Threshold is the maximum tolerated deviation between two prices
+	Mode         ThresholdMode // Mode determines the unit of Threshold
+	MinProviders int           // MinProviders is the minimum number of prices needed to compare a pair, at least two
+	MaxAge       time.Duration // MaxAge is the maximum age of an upstream quote, zero disables staleness detection
+	Strategy     Strategy      // Strategy determines how prices are compared, Pairwise by default
+	Quorum       int           // Quorum is the number of prices needed to establish consensus, fewer are reported as no consensus
+	Subject      string        // Subject is the provider under test in the Reference strategy
+	References   []string      // References are the providers Subject is compared with, in order of preference, all others when empty
+	Enabled      bool          // Enabled indicates whether the pair is compared at all
+
+	// ConfidenceTolerance tolerates deviations within the confidence intervals of the compared prices,
+	// only the deviation beyond them is measured against the threshold
+	ConfidenceTolerance bool
+
+	RaiseAfter         int           // RaiseAfter is the number of consecutive breaching cycles before a difference is raised
+	RaiseAfterDuration time.Duration // RaiseAfterDuration is the breaching duration after which a difference is raised
+	ClearAfter         int           // ClearAfter is the number of consecutive healthy cycles before a raised difference is cleared
+}
+
+// Exceeded reports whether the deviation exceeds the rule threshold.
+// absolute is the absolute difference and relative is the difference as a fraction of the reference price.
+func (r Rule) Exceeded(absolute, relative float64) bool {
+	switch r.Mode {
+	case Percent:
+		return relative*100 > r.Threshold
+	case BasisPoints:
+		return relative*10000 > r.Threshold
+	}
+	return absolute > r.Threshold
+}
+
+// exceededBeyond reports whether the deviation exceeds the rule threshold, with the deviation within confidence
+// tolerated if the rule enables ConfidenceTolerance. confidence is the combined half width of the confidence intervals
+// of the compared prices, in units of the quote coin.
+func (r Rule) exceededBeyond(absolute, relative, confidence float64) bool {
+	if r.ConfidenceTolerance && confidence > 0 {
+		if absolute <= confidence {
+			return false
+		}
+		relative *= (absolute - confidence) / absolute
+		absolute -= confidence
+	}
+	return r.Exceeded(absolute, relative)
+}
+
+// Exceedance returns the deviation measured in the unit of the threshold mode as a multiple of the threshold,
+// values above 1 exceed it. A zero threshold is exceeded by any deviation infinitely.
+func (r Rule) Exceedance(absolute, relative float64) float64 {
+	deviation := absolute
+	switch r.Mode {
+	case Percent:
+		deviation = relative * 100
+	case BasisPoints:
+		deviation = relative * 10000
+	}
+	if r.Threshold == 0 {
+		if deviation == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return deviation / r.Threshold
+}
+
+// persisted reports whether a difference observed for the given number of consecutive cycles and duration is raised.
+// Without RaiseAfter and RaiseAfterDuration differences are raised immediately, otherwise once either is reached.
+func (r Rule) persisted(cycles int, d time.Duration) bool {
+	if r.RaiseAfter <= 0 && r.RaiseAfterDuration <= 0 {
+		return true
+	}
+	return (r.RaiseAfter > 0 && cycles >= r.RaiseAfter) || (r.RaiseAfterDuration > 0 && d >= r.RaiseAfterDuration)
+}
+
+// compares reports whether the rule strategy compares the price of provider a, reported as ServiceA,
+// with the price of b, reported as ServiceB.
+func (r Rule) compares(a, b string) bool {
+	switch {
+	case r.Strategy.consensus():
+		return b == Consensus
+	case r.Strategy == Reference:
+		if !strings.EqualFold(a, r.Subject) {
+			return false
+		}
+		if len(r.References) == 0 {
+			return !strings.EqualFold(b, r.Subject)
+		}
+		return slices.ContainsFunc(r.References, func(ref string) bool { return strings.EqualFold(b, ref) })
+	}
+	return b != Consensus
+}
+
+// minProviders returns the effective minimum number of prices needed to compare a pair.
+func (r Rule) minProviders() int {
+	return max(r.MinProviders, 2)
+}
+
+// Rules holds the comparison Rule of each Pair.
+type Rules struct {
+	Default Rule          // Default applies to pairs without a rule of their own
+	Pairs   map[Pair]Rule // Pairs holds per pair rules
+}
+
+// For returns the rule applied to the given pair.
+func (r Rules) For(p Pair) Rule {
+	if rule, ok := r.Pairs[p]; ok {
+		return rule
+	}
+	return r.Default
+}
+
+// deviation returns the absolute difference between a and b and the difference relative to reference.
+func deviation(a, b, reference float64) (absolute, relative float64) {
+	absolute = math.Abs(a - b)
+	switch {
+	case reference != 0:
+		relative = absolute / math.Abs(reference)
+	case absolute != 0:
+		relative = math.Inf(1)
+	}
+	return absolute, relative
+}
@@ -19,13 +19,30 @@ var (
 	// PriceMonitorErrorCounterMetricName is the name of the Prometheus metric for sending a heartbeat signal of the price monitor.
 	PriceMonitorHeartbeatMetricName = "price_monitor_heartbeat"
 
+	// PriceMonitorProviderLatencyMetricName is the name of the Prometheus metric for measuring provider response times.
+	PriceMonitorProviderLatencyMetricName = "price_monitor_provider_latency_seconds"
+
+	// PriceMonitorProviderErrorsMetricName is the name of the Prometheus metric for measuring the number of failed provider requests.
+	PriceMonitorProviderErrorsMetricName = "price_monitor_provider_errors"
+
+	// PriceMonitorStalePricesMetricName is the name of the Prometheus metric for measuring the number of stale prices.
+	PriceMonitorStalePricesMetricName = "price_monitor_stale_prices"
+
+	// PriceMonitorNoConsensusMetricName is the name of the Prometheus metric for measuring the number of comparisons without quorum.
+	PriceMonitorNoConsensusMetricName = "price_monitor_no_consensus"
+
+	// PriceMonitorProviderUpMetricName is the name of the Prometheus metric reporting whether a provider returned prices in the last cycle.
+	PriceMonitorProviderUpMetricName = "price_monitor_provider_up"
+
 	// PricingErrorCounter is a Prometheus counter that measures the number of pricing errors.
 	// This metric can be used to monitor errors in the price monitor.
-	PricingErrorCounter = prometheus.NewCounter(
+	// It is labeled with the pair and the two providers whose prices differ and counts only differences raised by the Detector.
+	PricingErrorCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: PriceMonitorErrorCounterMetricName,
 			Help: "Total number of pricing errors",
 		},
+		[]string{"pair", "provider_a", "provider_b"},
 	)
 
 	// PricingHeartbeatCounter is a Prometheus counter that sends a heartbeat signal of the price monitor.
@@ -36,12 +53,64 @@ var (
 			Help: "Total number of pricing measurements",
 		},
 	)
+
+	// ProviderLatencyHistogram is a Prometheus histogram that measures how long each provider takes to return prices.
+	ProviderLatencyHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    PriceMonitorProviderLatencyMetricName,
+			Help:    "Time taken by a provider to return prices",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"provider"},
+	)
+
+	// StalePriceCounter is a Prometheus counter that measures the number of prices older than their rule allows.
+	StalePriceCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: PriceMonitorStalePricesMetricName,
+			Help: "Total number of stale prices",
+		},
+		[]string{"pair", "provider"},
+	)
+
+	// NoConsensusCounter is a Prometheus counter that measures the number of times too few providers answered to establish consensus.
+	NoConsensusCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: PriceMonitorNoConsensusMetricName,
+			Help: "Total number of comparisons without quorum",
+		},
+		[]string{"pair"},
+	)
+
+	// ProviderErrorCounter is a Prometheus counter that measures the number of failed provider requests.
+	// This metric can be used to alert on provider outages.
+	ProviderErrorCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: PriceMonitorProviderErrorsMetricName,
+			Help: "Total number of failed provider requests",
+		},
+		[]string{"provider"},
+	)
+
+	// ProviderUpGauge is a Prometheus gauge set to 1 when a provider returned prices in the last cycle and 0 when it failed.
+	ProviderUpGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: PriceMonitorProviderUpMetricName,
+			Help: "Whether the provider returned prices in the last cycle",
+		},
+		[]string{"provider"},
+	)
 )
 
 // init registers metrics with Prometheus
 func init() {
 	prometheus.MustRegister(PricingErrorCounter)
 	prometheus.MustRegister(PricingHeartbeatCounter)
+	prometheus.MustRegister(ProviderLatencyHistogram)
+	prometheus.MustRegister(StalePriceCounter)
+	prometheus.MustRegister(NoConsensusCounter)
+	prometheus.MustRegister(ProviderErrorCounter)
+	prometheus.MustRegister(ProviderUpGauge)
 }
 
 func NewOtelTracer(ctx context.Context, host string) (*sdktrace.TracerProvider, error) {